@@ -1,5 +1,94 @@
 package main
 
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Jadiscke/myown-sql/internal/format"
+)
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "fmt" {
+		if err := fmtCommand(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
 	println("Hello, World!")
 }
+
+// fmtCommand rewrites each SQL file in place, or formats stdin to stdout when
+// no files are given
+func fmtCommand(args []string) error {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	lower := flags.Bool("lower", false, "print keywords in lower case")
+	indent := flags.Int("indent", 2, "spaces to indent broken lists by")
+	width := flags.Int("width", 80, "line width past which lists are broken, 0 for never")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	options := format.Options{
+		KeywordCase: format.UpperCase,
+		Indent:      *indent,
+		Width:       *width,
+	}
+
+	if *lower {
+		options.KeywordCase = format.LowerCase
+	}
+
+	if flags.NArg() == 0 {
+		source, err := io.ReadAll(os.Stdin)
+
+		if err != nil {
+			return err
+		}
+
+		out, err := format.Format(string(source), options)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = os.Stdout.WriteString(out)
+
+		return err
+	}
+
+	for _, path := range flags.Args() {
+		source, err := os.ReadFile(path)
+
+		if err != nil {
+			return err
+		}
+
+		out, err := format.Format(string(source), options)
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		if out == string(source) {
+			continue
+		}
+
+		info, err := os.Stat(path)
+
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(path, []byte(out), info.Mode()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
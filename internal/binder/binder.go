@@ -0,0 +1,1120 @@
+package binder
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Jadiscke/myown-sql/internal/lexer"
+	"github.com/Jadiscke/myown-sql/internal/parser"
+)
+
+// Info is what binding found out about the tree
+type Info struct {
+	// Types holds the type of every expression
+	Types map[*parser.Expression]Type
+	// Columns maps every column reference to the column it names
+	Columns map[*parser.Expression]*Column
+}
+
+// relation is a table, WITH query or subquery whose columns are in scope
+type relation struct {
+	name    string
+	columns []*Column
+	// opaque is set for tables that couldn't be found, whose columns all
+	// resolve quietly to UnknownType
+	opaque bool
+	// qualified is set for a trigger's NEW and OLD rows, whose columns are
+	// only found when named with the row
+	qualified bool
+}
+
+type scope struct {
+	relations []*relation
+	// ctes are the WITH queries the scope can read from
+	ctes   map[string]*relation
+	parent *scope
+}
+
+func (s *scope) cte(name string) (*relation, bool) {
+	for ; s != nil; s = s.parent {
+		if r, ok := s.ctes[name]; ok {
+			return r, true
+		}
+	}
+
+	return nil, false
+}
+
+type binder struct {
+	catalog     *Catalog
+	info        *Info
+	diagnostics []parser.Diagnostic
+}
+
+func newBinder(catalog *Catalog) *binder {
+	return &binder{
+		catalog: catalog,
+		info: &Info{
+			Types:   map[*parser.Expression]Type{},
+			Columns: map[*parser.Expression]*Column{},
+		},
+	}
+}
+
+// Bind resolves the names in every statement against catalog and works out
+// the type of every expression. Statements that change tables and schemas
+// are applied to catalog as they're bound, so later statements see them
+func Bind(ast *parser.Ast, catalog *Catalog) (*Info, []parser.Diagnostic) {
+	b := newBinder(catalog)
+
+	for _, stmt := range ast.Statements {
+		b.bindStatement(stmt)
+	}
+
+	return b.info, b.diagnostics
+}
+
+// errorf reports a problem at t, or without a location when t is nil
+func (b *binder) errorf(t *lexer.Token, format string, args ...any) {
+	d := parser.Diagnostic{
+		Severity: parser.ErrorSeverity,
+		Message:  fmt.Sprintf(format, args...),
+	}
+
+	if t != nil {
+		d.Start = t.Loc
+		d.End = t.End()
+	}
+
+	b.diagnostics = append(b.diagnostics, d)
+}
+
+// anchor is the token errors about e point at, as near its start as the
+// tree records. It's nil if the tree records none
+func anchor(e *parser.Expression) *lexer.Token {
+	switch e.Kind {
+	case parser.LiteralKind:
+		if e.Table != nil {
+			return e.Table
+		}
+
+		return e.Literal
+	case parser.BinaryKind:
+		return anchor(&e.Binary.A)
+	case parser.UnaryKind:
+		return &e.Unary.Op
+	case parser.CallKind:
+		return &e.Call.Name
+	case parser.SubqueryKind:
+		slct := e.Subquery
+
+		for slct.SetOperation != nil {
+			slct = slct.SetOperation.Left
+		}
+
+		if len(slct.Item) == 0 {
+			return nil
+		}
+
+		return anchor(slct.Item[0])
+	case parser.CaseKind:
+		if e.Case.Operand != nil {
+			return anchor(e.Case.Operand)
+		}
+
+		return anchor(&e.Case.Whens[0].When)
+	case parser.CastKind:
+		if e.Cast.Op.Kind == lexer.KeywordKind {
+			return &e.Cast.Op
+		}
+
+		return anchor(&e.Cast.Operand)
+	case parser.BetweenKind:
+		return anchor(&e.Between.Operand)
+	}
+
+	return anchor(e.List[0])
+}
+
+func isStringLiteral(e *parser.Expression) bool {
+	return e != nil && e.Kind == parser.LiteralKind && e.Literal.Kind == lexer.StringKind
+}
+
+// unify is the type x and y are compared or combined as. A string literal
+// is read as a date or timestamp when compared with one
+func unify(x, y *parser.Expression, tx, ty Type) (Type, bool) {
+	if t, ok := commonType(tx, ty); ok {
+		return t, true
+	}
+
+	if isStringLiteral(x) && isTemporal(ty) {
+		return ty, true
+	}
+
+	if isStringLiteral(y) && isTemporal(tx) {
+		return tx, true
+	}
+
+	return UnknownType, false
+}
+
+// check reports e, of type t, unless it can be used as a want
+func (b *binder) check(e *parser.Expression, t Type, want Type, what string) bool {
+	if _, ok := unify(e, nil, t, want); !ok {
+		b.errorf(anchor(e), "Argument of %s must be %s, not %s", what, want, t)
+
+		return false
+	}
+
+	return true
+}
+
+// compare reports a and b unless op can compare them
+func (b *binder) compare(op *lexer.Token, x, y *parser.Expression, tx, ty Type) Type {
+	t, ok := unify(x, y, tx, ty)
+
+	if !ok {
+		b.errorf(op, "Operator %s can't compare %s with %s", strings.ToUpper(op.Value), tx, ty)
+	}
+
+	return t
+}
+
+// combine folds e, of type t, into the type acc of the values it's
+// combined with, as the branches of a CASE are
+func (b *binder) combine(what string, e *parser.Expression, t, acc Type) Type {
+	combined, ok := unify(e, nil, t, acc)
+
+	if !ok {
+		b.errorf(anchor(e), "%s types %s and %s cannot be matched", what, acc, t)
+
+		return acc
+	}
+
+	return combined
+}
+
+// assign reports e, of type t, unless it can be stored in column
+func (b *binder) assign(e *parser.Expression, t Type, column *Column) bool {
+	if _, ok := unify(e, nil, t, column.Type); !ok {
+		b.errorf(anchor(e), "Column %s is %s but the value is %s", column.Name, column.Type, t)
+
+		return false
+	}
+
+	return true
+}
+
+func (b *binder) bindStatement(stmt *parser.Statement) {
+	switch stmt.Kind {
+	case parser.SelectKind:
+		b.bindSelect(nil, stmt.SelectStatement)
+	case parser.InsertKind:
+		b.bindInsert(nil, stmt.InsertStatement)
+	case parser.CreateTableKind:
+		b.bindCreateTable(stmt.CreateTableStatement)
+	case parser.AlterTableKind:
+		b.bindAlterTable(stmt.AlterTableStatement)
+	case parser.CreateSchemaKind:
+		b.addSchema(&stmt.CreateSchemaStatement.Name, false)
+	case parser.DropSchemaKind:
+		b.dropSchema(&stmt.DropSchemaStatement.Name)
+	case parser.AttachKind:
+		b.addSchema(&stmt.AttachStatement.Name, true)
+	case parser.CreateViewKind:
+		b.bindCreateView(stmt.CreateViewStatement)
+	case parser.RefreshViewKind:
+		refresh := stmt.RefreshViewStatement
+
+		if view, ok := b.table(b.schema(refresh.Schema), refresh.Schema, &refresh.Name); ok && !view.Materialized {
+			b.errorf(&refresh.Name, "%s %s is not a materialized view", view.Kind(), view.Name)
+		}
+	case parser.DropViewKind:
+		b.bindDropView(stmt.DropViewStatement)
+	case parser.CreateTriggerKind:
+		b.bindCreateTrigger(stmt.CreateTriggerStatement)
+	}
+}
+
+func (b *binder) addSchema(name *lexer.Token, attached bool) {
+	if _, ok := b.catalog.Schema(name.Value); ok {
+		b.errorf(name, "Schema %s already exists", name.Value)
+
+		return
+	}
+
+	schema := NewSchema(name.Value)
+	schema.Attached = attached
+	b.catalog.AddSchema(schema)
+}
+
+func (b *binder) dropSchema(name *lexer.Token) {
+	schema := b.schema(name)
+
+	switch {
+	case schema == nil:
+		return
+	case schema.Name == DefaultSchema:
+		b.errorf(name, "Schema %s can't be dropped", name.Value)
+	case !schema.Empty():
+		b.errorf(name, "Schema %s is not empty", name.Value)
+	default:
+		b.catalog.DropSchema(schema.Name)
+	}
+}
+
+// schema finds the schema a table name is qualified by, or the default
+// schema for unqualified names. It's nil if there's no such schema
+func (b *binder) schema(name *lexer.Token) *Schema {
+	if name == nil {
+		schema, _ := b.catalog.Schema(DefaultSchema)
+
+		return schema
+	}
+
+	schema, ok := b.catalog.Schema(name.Value)
+
+	if !ok {
+		b.errorf(name, "Unknown schema %s", name.Value)
+	}
+
+	return schema
+}
+
+// table finds the table called name in schema. Tables that aren't known in
+// an attached database may still be in its file, so only the missing
+// tables of other schemas are reported
+func (b *binder) table(schema *Schema, qualifier, name *lexer.Token) (*Table, bool) {
+	if schema == nil {
+		return nil, false
+	}
+
+	table, ok := schema.Table(name.Value)
+
+	if !ok && !schema.Attached {
+		b.errorf(name, "Unknown table %s", qualifiedName(qualifier, name))
+	}
+
+	return table, ok
+}
+
+func qualifiedName(qualifier, name *lexer.Token) string {
+	if qualifier != nil {
+		return qualifier.Value + "." + name.Value
+	}
+
+	return name.Value
+}
+
+// bindSelect binds a query that can see the names in outer, and returns
+// its result columns
+func (b *binder) bindSelect(outer *scope, slct *parser.SelectStatement) []*Column {
+	s := &scope{parent: outer}
+
+	if slct.With != nil {
+		b.bindWith(s, slct.With)
+	}
+
+	var columns []*Column
+
+	if slct.SetOperation != nil {
+		columns = b.bindSetOperation(s, slct.SetOperation)
+
+		// ORDER BY of a compound query sorts by its result columns
+		b.bindOrderBy(&scope{relations: []*relation{{columns: columns}}, parent: s}, slct.OrderBy, columns)
+	} else {
+		if slct.From != nil {
+			s.relations = append(s.relations, b.bindFromItem(s, slct.From))
+		}
+
+		for _, e := range slct.DistinctOn {
+			b.bindExpression(s, e)
+		}
+
+		for _, e := range slct.Item {
+			if e.Star() {
+				columns = append(columns, b.bindStar(s, e)...)
+
+				continue
+			}
+
+			columns = append(columns, &Column{
+				Name: columnName(e),
+				Type: b.bindExpression(s, e),
+			})
+		}
+
+		if slct.Where != nil {
+			b.check(slct.Where, b.bindExpression(s, slct.Where), BooleanType, "WHERE")
+		}
+
+		b.bindOrderBy(s, slct.OrderBy, columns)
+	}
+
+	if slct.Limit != nil {
+		b.check(slct.Limit, b.bindExpression(s, slct.Limit), IntegerType, "LIMIT")
+	}
+
+	if slct.Offset != nil {
+		b.check(slct.Offset, b.bindExpression(s, slct.Offset), IntegerType, "OFFSET")
+	}
+
+	return columns
+}
+
+// bindStar expands a * or t.* select item to the columns it stands for.
+// Tables whose columns aren't known add none
+func (b *binder) bindStar(s *scope, e *parser.Expression) []*Column {
+	b.info.Types[e] = UnknownType
+
+	var columns []*Column
+
+	found := false
+
+	for _, r := range s.relations {
+		if e.Table != nil && r.name != e.Table.Value {
+			continue
+		}
+
+		found = true
+		columns = append(columns, r.columns...)
+	}
+
+	if e.Table != nil && !found {
+		b.errorf(e.Table, "Unknown table %s", e.Table.Value)
+	}
+
+	return columns
+}
+
+// columnName is the name a query result column can be referred to by
+func columnName(e *parser.Expression) string {
+	switch e.Kind {
+	case parser.LiteralKind:
+		if e.Literal.Kind == lexer.IdentifierKind || e.Literal.Kind == lexer.QuotedIdentifierKind {
+			return e.Literal.Value
+		}
+	case parser.CallKind:
+		return e.Call.Name.Value
+	case parser.CastKind:
+		return columnName(&e.Cast.Operand)
+	}
+
+	return ""
+}
+
+func (b *binder) bindWith(s *scope, with *parser.WithClause) {
+	s.ctes = map[string]*relation{}
+
+	for _, cte := range with.Ctes {
+		if with.Recursive && cte.Query.SetOperation != nil {
+			// The recursive term reads the rows of the initial term, so
+			// that is bound on its own first to learn their types
+			probe := newBinder(b.catalog)
+			s.ctes[cte.Name.Value] = probe.cteRelation(cte, probe.bindSelect(s, cte.Query.SetOperation.Left))
+		}
+
+		s.ctes[cte.Name.Value] = b.cteRelation(cte, b.bindSelect(s, cte.Query))
+	}
+}
+
+func (b *binder) cteRelation(cte *parser.CommonTableExpression, columns []*Column) *relation {
+	r := &relation{name: cte.Name.Value, columns: columns}
+
+	if cte.Columns == nil {
+		return r
+	}
+
+	if len(cte.Columns) != len(columns) {
+		b.errorf(&cte.Name, "WITH query %s has %d columns but %d names", cte.Name.Value, len(columns), len(cte.Columns))
+		r.opaque = true
+
+		return r
+	}
+
+	r.columns = nil
+
+	for i, name := range cte.Columns {
+		r.columns = append(r.columns, &Column{Name: name.Value, Type: columns[i].Type})
+	}
+
+	return r
+}
+
+func (b *binder) bindSetOperation(s *scope, op *parser.SetOperation) []*Column {
+	left := b.bindSelect(s, op.Left)
+	right := b.bindSelect(s, op.Right)
+
+	if len(left) != len(right) {
+		b.errorf(&op.Op, "Each %s query must have the same number of columns", strings.ToUpper(op.Op.Value))
+
+		return left
+	}
+
+	var columns []*Column
+
+	for i := range left {
+		t, ok := commonType(left[i].Type, right[i].Type)
+
+		if !ok {
+			b.errorf(&op.Op, "%s types %s and %s cannot be matched", strings.ToUpper(op.Op.Value), left[i].Type, right[i].Type)
+		}
+
+		columns = append(columns, &Column{Name: left[i].Name, Type: t})
+	}
+
+	return columns
+}
+
+func (b *binder) bindFromItem(s *scope, item *parser.FromItem) *relation {
+	r := &relation{}
+
+	if item.Kind == parser.SubqueryFromKind {
+		r.columns = b.bindSelect(s, item.Subquery)
+	} else if cte, ok := s.cte(item.Table.Value); ok && item.Schema == nil {
+		r.columns = cte.columns
+		r.opaque = cte.opaque
+	} else if table, ok := b.table(b.schema(item.Schema), item.Schema, item.Table); ok {
+		r.columns = table.Columns
+	} else {
+		r.opaque = true
+	}
+
+	if item.Alias != nil {
+		r.name = item.Alias.Value
+	} else if item.Table != nil {
+		r.name = item.Table.Value
+	}
+
+	return r
+}
+
+// bindOrderBy binds ORDER BY items, which may also be positions in the
+// result columns
+func (b *binder) bindOrderBy(s *scope, items []*parser.OrderByItem, columns []*Column) {
+	for _, item := range items {
+		if item.Exp.Kind == parser.LiteralKind && item.Exp.Literal.Kind == lexer.NumericKind {
+			position, err := strconv.Atoi(item.Exp.Literal.Value)
+
+			if err == nil {
+				if position < 1 || position > len(columns) {
+					b.errorf(item.Exp.Literal, "ORDER BY position %d is not in select list", position)
+					b.info.Types[item.Exp] = UnknownType
+				} else {
+					b.info.Types[item.Exp] = columns[position-1].Type
+				}
+
+				continue
+			}
+		}
+
+		b.bindExpression(s, item.Exp)
+	}
+}
+
+// bindInsert binds an INSERT whose values can see the names in outer
+func (b *binder) bindInsert(outer *scope, stmt *parser.InsertStatement) {
+	s := &scope{parent: outer}
+
+	if stmt.With != nil {
+		b.bindWith(s, stmt.With)
+	}
+
+	var values []*parser.Expression
+
+	if stmt.Values != nil {
+		values = *stmt.Values
+	}
+
+	table, ok := b.table(b.schema(stmt.Schema), stmt.Schema, &stmt.Table)
+
+	if ok && table.Query != nil {
+		b.errorf(&stmt.Table, "%s %s can't be inserted into", table.Kind(), table.Name)
+	} else if ok && len(values) != len(table.Columns) {
+		b.errorf(&stmt.Table, "INSERT has %d values but %s has %d columns", len(values), table.Name, len(table.Columns))
+	}
+
+	for i, v := range values {
+		t := b.bindExpression(s, v)
+
+		if ok && i < len(table.Columns) {
+			b.assign(v, t, table.Columns[i])
+		}
+	}
+}
+
+func (b *binder) bindCreateTable(stmt *parser.CreateTableStatement) {
+	schema := b.schema(stmt.Schema)
+
+	if schema == nil {
+		return
+	}
+
+	if existing, ok := schema.Table(stmt.Name.Value); ok {
+		b.errorf(&stmt.Name, "%s %s already exists", existing.Kind(), qualifiedName(stmt.Schema, &stmt.Name))
+
+		return
+	}
+
+	table := &Table{Name: stmt.Name.Value}
+	ok := true
+
+	if stmt.Cols != nil {
+		for _, def := range *stmt.Cols {
+			ok = b.addColumn(table, def) && ok
+		}
+	}
+
+	if ok {
+		schema.AddTable(table)
+	}
+}
+
+// addColumn checks a column definition and adds it to table
+func (b *binder) addColumn(table *Table, def *parser.ColumnDefinition) bool {
+	if _, ok := table.Column(def.Name.Value); ok {
+		b.errorf(&def.Name, "Column %s already exists in %s", def.Name.Value, table.Name)
+
+		return false
+	}
+
+	column := &Column{Name: def.Name.Value, Type: TypeOf(def.Datatype)}
+
+	if def.Default != nil && !b.assign(def.Default, b.bindExpression(&scope{}, def.Default), column) {
+		return false
+	}
+
+	table.Columns = append(table.Columns, column)
+
+	return true
+}
+
+func (b *binder) bindAlterTable(stmt *parser.AlterTableStatement) {
+	schema := b.schema(stmt.Schema)
+	table, ok := b.table(schema, stmt.Schema, &stmt.Name)
+
+	if !ok {
+		return
+	}
+
+	if table.Query != nil {
+		b.errorf(&stmt.Name, "%s %s can't be altered", table.Kind(), table.Name)
+
+		return
+	}
+
+	// Tables and columns are replaced rather than changed, so Info from
+	// earlier statements keeps describing what they saw
+	altered := &Table{Name: table.Name, Columns: append([]*Column(nil), table.Columns...), Triggers: table.Triggers}
+
+	switch stmt.Kind {
+	case parser.AddColumnKind:
+		if !b.addColumn(altered, stmt.AddColumn) {
+			return
+		}
+	case parser.DropColumnKind, parser.RenameColumnKind:
+		i := 0
+
+		for i < len(altered.Columns) && altered.Columns[i].Name != stmt.Column.Value {
+			i++
+		}
+
+		if i == len(altered.Columns) {
+			b.errorf(&stmt.Column, "Unknown column %s in %s", stmt.Column.Value, table.Name)
+
+			return
+		}
+
+		if stmt.Kind == parser.DropColumnKind {
+			altered.Columns = append(altered.Columns[:i], altered.Columns[i+1:]...)
+
+			break
+		}
+
+		if _, ok := altered.Column(stmt.NewName.Value); ok {
+			b.errorf(&stmt.NewName, "Column %s already exists in %s", stmt.NewName.Value, table.Name)
+
+			return
+		}
+
+		altered.Columns[i] = &Column{Name: stmt.NewName.Value, Type: altered.Columns[i].Type}
+	case parser.RenameTableKind:
+		if _, ok := schema.Table(stmt.NewName.Value); ok {
+			b.errorf(&stmt.NewName, "Table %s already exists", stmt.NewName.Value)
+
+			return
+		}
+
+		schema.DropTable(table.Name)
+		altered.Name = stmt.NewName.Value
+	}
+
+	schema.AddTable(altered)
+}
+
+func (b *binder) bindCreateView(stmt *parser.CreateViewStatement) {
+	schema := b.schema(stmt.Schema)
+	columns := b.bindSelect(nil, stmt.Query)
+
+	if schema == nil {
+		return
+	}
+
+	if existing, ok := schema.Table(stmt.Name.Value); ok {
+		b.errorf(&stmt.Name, "%s %s already exists", existing.Kind(), qualifiedName(stmt.Schema, &stmt.Name))
+
+		return
+	}
+
+	if stmt.Columns != nil && len(stmt.Columns) != len(columns) {
+		b.errorf(&stmt.Name, "View %s has %d columns but %d names", stmt.Name.Value, len(columns), len(stmt.Columns))
+
+		return
+	}
+
+	view := &Table{Name: stmt.Name.Value, Query: stmt.Query, Materialized: stmt.Materialized}
+
+	for i, column := range columns {
+		name := column.Name
+
+		if stmt.Columns != nil {
+			name = stmt.Columns[i].Value
+		}
+
+		view.Columns = append(view.Columns, &Column{Name: name, Type: column.Type})
+	}
+
+	schema.AddTable(view)
+}
+
+// bindCreateTrigger binds the trigger's condition and body with the row it
+// runs for in scope: NEW for the row an INSERT or UPDATE writes, OLD for
+// the row an UPDATE or DELETE replaces
+func (b *binder) bindCreateTrigger(stmt *parser.CreateTriggerStatement) {
+	schema := b.schema(stmt.Schema)
+	table, ok := b.table(schema, stmt.Schema, &stmt.Table)
+	s := &scope{}
+
+	if ok && table.Query != nil {
+		b.errorf(&stmt.Table, "%s %s can't have triggers", table.Kind(), table.Name)
+
+		ok = false
+	}
+
+	if !ok {
+		s.relations = []*relation{{name: "new", opaque: true}, {name: "old", opaque: true}}
+	} else {
+		event := lexer.Keyword(stmt.Event.Value)
+
+		if event != lexer.DeleteKeyword {
+			s.relations = append(s.relations, &relation{name: "new", columns: table.Columns, qualified: true})
+		}
+
+		if event != lexer.InsertKeyword {
+			s.relations = append(s.relations, &relation{name: "old", columns: table.Columns, qualified: true})
+		}
+	}
+
+	if stmt.When != nil {
+		b.check(stmt.When, b.bindExpression(s, stmt.When), BooleanType, "WHEN")
+	}
+
+	for _, body := range stmt.Body {
+		switch body.Kind {
+		case parser.SelectKind:
+			b.bindSelect(s, body.SelectStatement)
+		case parser.InsertKind:
+			b.bindInsert(s, body.InsertStatement)
+		}
+	}
+
+	if !ok {
+		return
+	}
+
+	if _, exists := table.Trigger(stmt.Name.Value); exists {
+		b.errorf(&stmt.Name, "Trigger %s already exists on %s", stmt.Name.Value, table.Name)
+
+		return
+	}
+
+	// Like ALTER TABLE, the table is replaced rather than changed
+	triggered := *table
+	triggered.Triggers = append(append([]*parser.CreateTriggerStatement(nil), table.Triggers...), stmt)
+	schema.AddTable(&triggered)
+}
+
+func (b *binder) bindDropView(stmt *parser.DropViewStatement) {
+	schema := b.schema(stmt.Schema)
+	view, ok := b.table(schema, stmt.Schema, &stmt.Name)
+
+	if !ok {
+		return
+	}
+
+	if view.Query == nil || view.Materialized != stmt.Materialized {
+		want := "a view"
+
+		if stmt.Materialized {
+			want = "a materialized view"
+		}
+
+		b.errorf(&stmt.Name, "%s %s is not %s", view.Kind(), view.Name, want)
+
+		return
+	}
+
+	schema.DropTable(view.Name)
+}
+
+func (b *binder) bindExpression(s *scope, e *parser.Expression) Type {
+	t := b.expressionType(s, e)
+	b.info.Types[e] = t
+
+	return t
+}
+
+func (b *binder) expressionType(s *scope, e *parser.Expression) Type {
+	switch e.Kind {
+	case parser.LiteralKind:
+		return b.bindLiteral(s, e)
+	case parser.BinaryKind:
+		return b.bindBinary(s, e)
+	case parser.UnaryKind:
+		operand := &e.Unary.Operand
+
+		if e.Unary.Op.Value == string(lexer.ExistsKeyword) {
+			// EXISTS only asks whether there are rows, so its subquery may
+			// return any number of columns
+			b.bindSelect(s, operand.Subquery)
+			b.info.Types[operand] = UnknownType
+
+			return BooleanType
+		}
+
+		b.check(operand, b.bindExpression(s, operand), BooleanType, "NOT")
+
+		return BooleanType
+	case parser.CallKind:
+		return b.bindCall(s, e.Call)
+	case parser.SubqueryKind:
+		return b.bindScalarSubquery(s, e)
+	case parser.CaseKind:
+		return b.bindCase(s, e.Case)
+	case parser.CastKind:
+		b.bindExpression(s, &e.Cast.Operand)
+
+		return TypeOf(e.Cast.Type)
+	case parser.BetweenKind:
+		between := e.Between
+		operand := b.bindExpression(s, &between.Operand)
+
+		for _, bound := range []*parser.Expression{&between.Low, &between.High} {
+			if _, ok := unify(&between.Operand, bound, operand, b.bindExpression(s, bound)); !ok {
+				b.errorf(anchor(bound), "BETWEEN can't compare %s with %s", operand, b.info.Types[bound])
+			}
+		}
+
+		return BooleanType
+	}
+
+	// Lists only appear after IN, which binds them
+	return UnknownType
+}
+
+// bindScalarSubquery binds a subquery used as a value, which must return a
+// single column
+func (b *binder) bindScalarSubquery(s *scope, e *parser.Expression) Type {
+	columns := b.bindSelect(s, e.Subquery)
+
+	if len(columns) != 1 {
+		b.errorf(anchor(e), "Subquery must return one column, not %d", len(columns))
+
+		return UnknownType
+	}
+
+	return columns[0].Type
+}
+
+func (b *binder) bindLiteral(s *scope, e *parser.Expression) Type {
+	switch e.Literal.Kind {
+	case lexer.IdentifierKind, lexer.QuotedIdentifierKind:
+		return b.bindColumn(s, e)
+	case lexer.NumericKind:
+		if strings.ContainsAny(e.Literal.Value, ".eE") {
+			return NumericType
+		}
+
+		return IntegerType
+	case lexer.StringKind:
+		return TextType
+	case lexer.BoolKind:
+		return BooleanType
+	}
+
+	return UnknownType
+}
+
+// bindColumn resolves a column reference in the innermost scope that has
+// it, so subqueries can refer to the columns of the queries around them
+func (b *binder) bindColumn(s *scope, e *parser.Expression) Type {
+	name := e.Literal.Value
+
+	if e.Table != nil {
+		name = e.Table.Value + "." + name
+	}
+
+	for ; s != nil; s = s.parent {
+		var matches []*Column
+
+		tableFound := false
+
+		for _, r := range s.relations {
+			if e.Table != nil && r.name != e.Table.Value || e.Table == nil && r.qualified {
+				continue
+			}
+
+			if r.opaque {
+				return UnknownType
+			}
+
+			tableFound = true
+
+			for _, c := range r.columns {
+				if c.Name == e.Literal.Value {
+					matches = append(matches, c)
+				}
+			}
+		}
+
+		if len(matches) > 1 {
+			b.errorf(e.Literal, "Column %s is ambiguous", name)
+
+			return UnknownType
+		}
+
+		if len(matches) == 1 {
+			b.info.Columns[e] = matches[0]
+
+			return matches[0].Type
+		}
+
+		if tableFound && e.Table != nil {
+			b.errorf(e.Literal, "Unknown column %s", name)
+
+			return UnknownType
+		}
+	}
+
+	if e.Table != nil {
+		b.errorf(e.Table, "Unknown table %s", e.Table.Value)
+	} else {
+		b.errorf(e.Literal, "Unknown column %s", name)
+	}
+
+	return UnknownType
+}
+
+func (b *binder) bindBinary(s *scope, e *parser.Expression) Type {
+	binary := e.Binary
+	op := &binary.Op
+
+	if op.Value == string(lexer.InKeyword) {
+		a := b.bindExpression(s, &binary.A)
+
+		if binary.B.Kind == parser.SubqueryKind {
+			b.compare(op, &binary.A, nil, a, b.bindExpression(s, &binary.B))
+
+			return BooleanType
+		}
+
+		// Each value is compared with the operand, not with each other
+		for _, item := range binary.B.List {
+			b.compare(op, &binary.A, item, a, b.bindExpression(s, item))
+		}
+
+		b.info.Types[&binary.B] = a
+
+		return BooleanType
+	}
+
+	a := b.bindExpression(s, &binary.A)
+	other := b.bindExpression(s, &binary.B)
+
+	switch op.Value {
+	case string(lexer.AndKeyword), string(lexer.OrKeyword):
+		b.check(&binary.A, a, BooleanType, strings.ToUpper(op.Value))
+		b.check(&binary.B, other, BooleanType, strings.ToUpper(op.Value))
+	case string(lexer.LikeKeyword), string(lexer.IlikeKeyword):
+		b.check(&binary.A, a, TextType, strings.ToUpper(op.Value))
+		b.check(&binary.B, other, TextType, strings.ToUpper(op.Value))
+
+		if binary.Escape != nil {
+			b.check(binary.Escape, b.bindExpression(s, binary.Escape), TextType, "ESCAPE")
+		}
+	case string(lexer.IsKeyword):
+		// IS NULL takes any value, IS TRUE and IS FALSE a boolean
+		if binary.B.Literal.Kind == lexer.BoolKind {
+			b.check(&binary.A, a, BooleanType, "IS")
+		}
+	case string(lexer.ConcatSymbol):
+		return TextType
+	default:
+		// The comparisons, and IS DISTINCT FROM
+		b.compare(op, &binary.A, &binary.B, a, other)
+	}
+
+	return BooleanType
+}
+
+func (b *binder) bindCase(s *scope, c *parser.CaseExpression) Type {
+	var operand Type
+
+	if c.Operand != nil {
+		operand = b.bindExpression(s, c.Operand)
+	}
+
+	result := UnknownType
+
+	for _, w := range c.Whens {
+		when := b.bindExpression(s, &w.When)
+
+		if c.Operand == nil {
+			b.check(&w.When, when, BooleanType, "CASE WHEN")
+		} else if _, ok := unify(c.Operand, &w.When, operand, when); !ok {
+			b.errorf(anchor(&w.When), "CASE can't compare %s with %s", operand, when)
+		}
+
+		result = b.combine("CASE", &w.Then, b.bindExpression(s, &w.Then), result)
+	}
+
+	if c.Else != nil {
+		result = b.combine("CASE", c.Else, b.bindExpression(s, c.Else), result)
+	}
+
+	return result
+}
+
+// arguments reports call unless it has between min and max arguments
+func (b *binder) arguments(call *parser.CallExpression, min, max int) bool {
+	if n := len(call.Args); n < min || max >= 0 && n > max {
+		expected := strconv.Itoa(min)
+
+		switch {
+		case max < 0:
+			expected = "at least " + expected
+		case max != min:
+			expected += " to " + strconv.Itoa(max)
+		}
+
+		b.errorf(&call.Name, "%s takes %s arguments, not %d", strings.ToUpper(call.Name.Value), expected, n)
+
+		return false
+	}
+
+	return true
+}
+
+// bindCall checks a call against the built-in it names
+func (b *binder) bindCall(s *scope, call *parser.CallExpression) Type {
+	name := call.Name.Value
+
+	if name == string(lexer.ExtractKeyword) {
+		// The first argument names the field, it isn't a column
+		b.info.Types[call.Args[0]] = TextType
+		b.check(call.Args[1], b.bindExpression(s, call.Args[1]), TimestampType, "EXTRACT")
+
+		return NumericType
+	}
+
+	var args []Type
+
+	for _, arg := range call.Args {
+		args = append(args, b.bindExpression(s, arg))
+	}
+
+	if call.Over != nil {
+		b.bindWindow(s, call.Over)
+	}
+
+	f, ok := functions[name]
+
+	if !ok {
+		b.errorf(&call.Name, "Unknown function %s", name)
+
+		return UnknownType
+	}
+
+	if f.window && call.Over == nil {
+		b.errorf(&call.Name, "Window function %s requires an OVER clause", strings.ToUpper(name))
+	}
+
+	if !b.arguments(call, f.min, f.max) {
+		return f.result
+	}
+
+	what := strings.ToUpper(name)
+	valid := true
+
+	for i, arg := range call.Args {
+		if arg.Star() {
+			if !f.star {
+				b.errorf(arg.Literal, "%s can't take *", what)
+
+				valid = false
+			}
+
+			continue
+		}
+
+		valid = b.check(arg, args[i], f.param(i), what) && valid
+	}
+
+	if f.compared && valid {
+		b.compare(&call.Name, call.Args[0], call.Args[1], args[0], args[1])
+	}
+
+	switch {
+	case !valid:
+		return f.result
+	case f.matched:
+		t := UnknownType
+
+		for i, arg := range call.Args {
+			t = b.combine(what, arg, args[i], t)
+		}
+
+		return t
+	case f.result == UnknownType && len(args) > 0:
+		return args[0]
+	}
+
+	return f.result
+}
+
+func (b *binder) bindWindow(s *scope, w *parser.WindowDefinition) {
+	for _, e := range w.PartitionBy {
+		b.bindExpression(s, e)
+	}
+
+	for _, item := range w.OrderBy {
+		b.bindExpression(s, item.Exp)
+	}
+
+	if w.Frame == nil {
+		return
+	}
+
+	for _, bound := range []*parser.FrameBound{&w.Frame.Start, w.Frame.End} {
+		if bound == nil || bound.Offset == nil {
+			continue
+		}
+
+		t := b.bindExpression(s, bound.Offset)
+
+		if w.Frame.Unit.Value == string(lexer.RowsKeyword) {
+			b.check(bound.Offset, t, IntegerType, "ROWS")
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package binder
+
+import (
+	"testing"
+
+	"github.com/Jadiscke/myown-sql/internal/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+const schema = `CREATE TABLE users (id INT, name TEXT, email VARCHAR(100), created TIMESTAMP, active BOOLEAN);
+CREATE TABLE orders (id BIGINT, user_id INT, total NUMERIC(10, 2), placed DATE);`
+
+// bind binds input against the tables of schema
+func bind(t *testing.T, input string) (*parser.Ast, *Info, []string) {
+	catalog := NewCatalog()
+	tables, err := parser.Parse(schema)
+	assert.Nil(t, err)
+
+	_, diagnostics := Bind(tables, catalog)
+	assert.Nil(t, diagnostics)
+
+	ast, err := parser.Parse(input)
+	assert.Nil(t, err, input)
+
+	if err != nil {
+		return nil, nil, nil
+	}
+
+	info, diagnostics := Bind(ast, catalog)
+
+	var errors []string
+
+	for _, d := range diagnostics {
+		errors = append(errors, d.Error())
+	}
+
+	return ast, info, errors
+}
+
+func TestBind(t *testing.T) {
+	tests := []struct {
+		input  string
+		errors []string
+	}{
+		{
+			input: "SELECT id, name FROM users WHERE active AND name LIKE 'a%' ORDER BY 2 LIMIT 10;",
+		},
+		{
+			input: "SELECT u.id, users.name FROM users AS u WHERE u.created > '2024-01-01';",
+			errors: []string{
+				"[0,13]: Unknown table users",
+			},
+		},
+		{
+			input: "SELECT nme, u.emial FROM users AS u;",
+			errors: []string{
+				"[0,7]: Unknown column nme",
+				"[0,14]: Unknown column u.emial",
+			},
+		},
+		{
+			input: "SELECT id FROM (SELECT id, id FROM users) AS s;",
+			errors: []string{
+				"[0,7]: Column id is ambiguous",
+			},
+		},
+		{
+			input: "SELECT id FROM users WHERE name = 1 OR id;",
+			errors: []string{
+				"[0,32]: Operator = can't compare text with integer",
+				"[0,39]: Argument of OR must be boolean, not integer",
+			},
+		},
+		{
+			input: "SELECT id FROM users WHERE id IN (SELECT user_id FROM orders WHERE total > 1 AND user_id = users.id) AND EXISTS (SELECT 1 FROM orders);",
+		},
+		{
+			input: "SELECT id FROM users WHERE id IN (1, 'x', 2.5) AND name IN (SELECT total FROM orders);",
+			errors: []string{
+				"[0,30]: Operator IN can't compare integer with text",
+				"[0,56]: Operator IN can't compare text with numeric",
+			},
+		},
+		{
+			input: "SELECT CASE WHEN active THEN name ELSE id END, coalesce(name, email), nullif(id, 'x') FROM users WHERE name;",
+			errors: []string{
+				"[0,39]: CASE types text and integer cannot be matched",
+				"[0,70]: Operator NULLIF can't compare integer with text",
+				"[0,103]: Argument of WHERE must be boolean, not text",
+			},
+		},
+		{
+			input: "SELECT sum(total), sum(name), lower(id), foo(1) FROM orders;",
+			errors: []string{
+				"[0,23]: Unknown column name",
+				"[0,36]: Argument of LOWER must be text, not integer",
+				"[0,41]: Unknown function foo",
+			},
+		},
+		{
+			input: "SELECT user_id FROM orders UNION SELECT name FROM users ORDER BY 2;",
+			errors: []string{
+				"[0,27]: UNION types integer and text cannot be matched",
+				"[0,65]: ORDER BY position 2 is not in select list",
+			},
+		},
+		{
+			input: "WITH big AS (SELECT user_id, total FROM orders WHERE total > 100) SELECT b.user_id FROM big AS b WHERE b.total BETWEEN 1 AND 'x';",
+			errors: []string{
+				"[0,125]: BETWEEN can't compare numeric with text",
+			},
+		},
+		{
+			input: "WITH RECURSIVE r (n) AS (SELECT id FROM users UNION SELECT n FROM r WHERE n < 10) SELECT n FROM r;",
+		},
+		{
+			input: "SELECT row_number() OVER (PARTITION BY user_id ORDER BY placed ROWS BETWEEN 'x' PRECEDING AND CURRENT ROW) FROM orders;",
+			errors: []string{
+				"[0,76]: Argument of ROWS must be integer, not text",
+			},
+		},
+		{
+			input: "SELECT EXTRACT(year FROM placed), date_trunc('month', created) FROM orders;",
+			errors: []string{
+				"[0,54]: Unknown column created",
+			},
+		},
+		{
+			input: "INSERT INTO users VALUES (1, 'a', 'a@b', '2024-01-01', TRUE); INSERT INTO users VALUES ('x', 1); INSERT INTO nope VALUES (1);",
+			errors: []string{
+				"[0,74]: INSERT has 2 values but users has 5 columns",
+				"[0,88]: Column id is integer but the value is text",
+				"[0,93]: Column name is text but the value is integer",
+				"[0,109]: Unknown table nope",
+			},
+		},
+		{
+			input: "CREATE TABLE users (id INT); CREATE TABLE t (a INT, a TEXT, b INT DEFAULT 'x');",
+			errors: []string{
+				"[0,13]: Table users already exists",
+				"[0,52]: Column a already exists in t",
+				"[0,74]: Column b is integer but the value is text",
+			},
+		},
+		{
+			input: "ALTER TABLE users RENAME COLUMN name TO full_name; SELECT name FROM users; ALTER TABLE users DROP COLUMN email; SELECT email FROM users; ALTER TABLE orders RENAME TO purchases; SELECT total FROM purchases; SELECT total FROM orders;",
+			errors: []string{
+				"[0,58]: Unknown column name",
+				"[0,119]: Unknown column email",
+				"[0,224]: Unknown table orders",
+			},
+		},
+		{
+			input: "ALTER TABLE users ADD COLUMN id INT; ALTER TABLE users DROP COLUMN nope; ALTER TABLE nope RENAME TO x;",
+			errors: []string{
+				"[0,29]: Column id already exists in users",
+				"[0,67]: Unknown column nope in users",
+				"[0,85]: Unknown table nope",
+			},
+		},
+		{
+			input: "CREATE SCHEMA audit; CREATE TABLE audit.log (at TIMESTAMP); SELECT at FROM audit.log; SELECT at FROM log; DROP SCHEMA audit; DROP SCHEMA main; CREATE SCHEMA main;",
+			errors: []string{
+				"[0,101]: Unknown table log",
+				"[0,118]: Schema audit is not empty",
+				"[0,137]: Schema main can't be dropped",
+				"[0,157]: Schema main already exists",
+			},
+		},
+		{
+			input: "ATTACH 'archive.db' AS archive; SELECT a.id, a.anything FROM archive.users AS a WHERE a.id = 1; INSERT INTO archive.users VALUES (1); SELECT id FROM nope.users; CREATE SCHEMA s; DROP SCHEMA s; CREATE TABLE s.t (a INT);",
+			errors: []string{
+				"[0,149]: Unknown schema nope",
+				"[0,206]: Unknown schema s",
+			},
+		},
+		{
+			input: "CREATE VIEW active (user_id, who) AS SELECT id, name FROM users WHERE active; SELECT who FROM active WHERE user_id > 1; SELECT name FROM active; CREATE VIEW bad (a) AS SELECT id, nme FROM users;",
+			errors: []string{
+				"[0,127]: Unknown column name",
+				"[0,179]: Unknown column nme",
+				"[0,157]: View bad has 2 columns but 1 names",
+			},
+		},
+		{
+			input: "CREATE MATERIALIZED VIEW totals AS SELECT user_id, sum(total) FROM orders; SELECT sum FROM totals; REFRESH MATERIALIZED VIEW totals; REFRESH MATERIALIZED VIEW users; INSERT INTO totals VALUES (1, 2); DROP VIEW totals; DROP MATERIALIZED VIEW totals; SELECT sum FROM totals;",
+			errors: []string{
+				"[0,159]: Table users is not a materialized view",
+				"[0,178]: Materialized view totals can't be inserted into",
+				"[0,210]: Materialized view totals is not a view",
+				"[0,265]: Unknown table totals",
+			},
+		},
+		{
+			input: "CREATE VIEW v AS SELECT id FROM users; CREATE TABLE v (a INT); ALTER TABLE v ADD COLUMN b INT; DROP VIEW users;",
+			errors: []string{
+				"[0,52]: View v already exists",
+				"[0,75]: View v can't be altered",
+				"[0,105]: Table users is not a view",
+			},
+		},
+		{
+			input: "CREATE TRIGGER log AFTER UPDATE ON users FOR EACH ROW WHEN OLD.name <> NEW.name BEGIN INSERT INTO orders VALUES (1, NEW.id, 0, NEW.created); SELECT id FROM orders WHERE user_id = OLD.id AND NEW.nope; END; CREATE TRIGGER log BEFORE DELETE ON users FOR EACH ROW BEGIN SELECT NEW.id; END;",
+			errors: []string{
+				"[0,194]: Unknown column new.nope",
+				"[0,273]: Unknown table new",
+				"[0,220]: Trigger log already exists on users",
+			},
+		},
+		{
+			input: "CREATE TRIGGER t BEFORE INSERT ON users FOR EACH ROW WHEN NEW.name BEGIN SELECT OLD.id; SELECT id FROM orders WHERE NEW.id = id; END; CREATE VIEW v AS SELECT id FROM users; CREATE TRIGGER t AFTER INSERT ON v FOR EACH ROW BEGIN SELECT NEW.id; END; CREATE TRIGGER t AFTER INSERT ON nope FOR EACH ROW BEGIN SELECT NEW.id; END;",
+			errors: []string{
+				"[0,58]: Argument of WHEN must be boolean, not text",
+				"[0,80]: Unknown table old",
+				"[0,206]: View v can't have triggers",
+				"[0,280]: Unknown table nope",
+			},
+		},
+		{
+			input: `CREATE TABLE "My""T" (a INT); SELECT a FROM "My""T"; SELECT a FROM "My""""T";`,
+			errors: []string{
+				`[0,67]: Unknown table My""T`,
+			},
+		},
+		{
+			input: "SELECT * FROM users ORDER BY 5; SELECT u.* FROM users AS u ORDER BY 6; SELECT x.* FROM users; CREATE VIEW v AS SELECT *, 1 FROM orders; SELECT placed FROM v; SELECT * FROM users UNION SELECT * FROM orders;",
+			errors: []string{
+				"[0,68]: ORDER BY position 6 is not in select list",
+				"[0,78]: Unknown table x",
+				"[0,178]: Each UNION query must have the same number of columns",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		_, _, errors := bind(t, test.input)
+		assert.Equal(t, test.errors, errors, test.input)
+	}
+}
+
+func TestBind_functions(t *testing.T) {
+	tests := []struct {
+		call   string
+		typ    Type
+		errors []string
+	}{
+		{call: "count(name)", typ: IntegerType},
+		{call: "count(*) OVER (PARTITION BY active)", typ: IntegerType},
+		{call: "sum(*)", errors: []string{"[0,11]: SUM can't take *"}},
+		{call: "sum(id)", typ: IntegerType},
+		{call: "sum(name)", errors: []string{"[0,11]: Argument of SUM must be numeric, not text"}},
+		{call: "avg(id)", typ: NumericType},
+		{call: "min(created)", typ: TimestampType},
+		{call: "max(name)", typ: TextType},
+		{call: "row_number() OVER (ORDER BY id)", typ: IntegerType},
+		{call: "rank() OVER (ORDER BY id)", typ: IntegerType},
+		{call: "dense_rank(id) OVER (ORDER BY id)", typ: IntegerType, errors: []string{"[0,7]: DENSE_RANK takes 0 arguments, not 1"}},
+		{call: "lag(name, 1, 'none') OVER (ORDER BY id)", typ: TextType},
+		{call: "lead(name, 'x') OVER (ORDER BY id)", errors: []string{"[0,18]: Argument of LEAD must be integer, not text"}},
+		{call: "first_value(created) OVER (PARTITION BY active)", typ: TimestampType},
+		{call: "row_number()", typ: IntegerType, errors: []string{"[0,7]: Window function ROW_NUMBER requires an OVER clause"}},
+		{call: "lag(name)", typ: TextType, errors: []string{"[0,7]: Window function LAG requires an OVER clause"}},
+		{call: "lag(name) OVER (PARTITION BY active ORDER BY created)", typ: TextType},
+		{call: "coalesce(id, 1.5)", typ: NumericType},
+		{call: "nullif(name, '')", typ: TextType},
+		{call: "lower(name)", typ: TextType},
+		{call: "upper(name)", typ: TextType},
+		{call: "length(email)", typ: IntegerType},
+		{call: "substr(name, 1, 2)", typ: TextType},
+		{call: "substr(name)", typ: TextType, errors: []string{"[0,7]: SUBSTR takes 2 to 3 arguments, not 1"}},
+		{call: "trim(name, ' ')", typ: TextType},
+		{call: "replace(name, 'a', 'b')", typ: TextType},
+		{call: "abs(id)", typ: IntegerType},
+		{call: "round(1.5, 1)", typ: NumericType},
+		{call: "floor(active)", errors: []string{"[0,13]: Argument of FLOOR must be numeric, not boolean"}},
+		{call: "mod(id, 2.5)", typ: NumericType},
+		{call: "now()", typ: TimestampType},
+		{call: "date_trunc('day', created)", typ: TimestampType},
+		{call: "EXTRACT(year FROM created)", typ: NumericType},
+		{call: "coalesce()", errors: []string{"[0,7]: COALESCE takes at least 1 arguments, not 0"}},
+	}
+
+	for _, test := range tests {
+		ast, info, errors := bind(t, "SELECT "+test.call+" FROM users;")
+		assert.Equal(t, test.errors, errors, test.call)
+		assert.Equal(t, test.typ, info.Types[ast.Statements[0].SelectStatement.Item[0]], test.call)
+	}
+}
+
+func TestBind_emptySubquery(t *testing.T) {
+	ast, err := parser.Parse("SELECT id FROM users WHERE (SELECT id FROM users);")
+	assert.Nil(t, err)
+
+	// The parser doesn't allow an empty select list, but trees can be built
+	// by hand
+	ast.Statements[0].SelectStatement.Where.Subquery.Item = nil
+
+	catalog := NewCatalog()
+	tables, _ := parser.Parse(schema)
+	Bind(tables, catalog)
+
+	_, diagnostics := Bind(ast, catalog)
+	assert.Equal(t, []parser.Diagnostic{{Severity: parser.ErrorSeverity, Message: "Subquery must return one column, not 0"}}, diagnostics)
+}
+
+func TestBind_info(t *testing.T) {
+	ast, info, errors := bind(t, "SELECT u.id, name || 'x', id = 1.5, (SELECT max(total) FROM orders), CAST(name AS DATE) FROM users AS u;")
+	assert.Nil(t, errors)
+
+	items := ast.Statements[0].SelectStatement.Item
+	var types []Type
+
+	for _, item := range items {
+		types = append(types, info.Types[item])
+	}
+
+	assert.Equal(t, []Type{IntegerType, TextType, BooleanType, NumericType, DateType}, types)
+
+	assert.Equal(t, &Column{Name: "id", Type: IntegerType}, info.Columns[items[0]])
+	assert.Equal(t, TextType, info.Types[&items[1].Binary.A])
+
+	// Every expression gets a type
+	parser.Inspect(ast, func(node parser.Node) bool {
+		if e, ok := node.(*parser.Expression); ok {
+			_, typed := info.Types[e]
+			assert.True(t, typed, e)
+		}
+
+		return true
+	})
+}
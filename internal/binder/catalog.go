@@ -0,0 +1,190 @@
+package binder
+
+import (
+	"github.com/Jadiscke/myown-sql/internal/lexer"
+	"github.com/Jadiscke/myown-sql/internal/parser"
+)
+
+// Type is what the binder works out an expression evaluates to
+type Type uint
+
+const (
+	// UnknownType is the type of NULL, and of anything an error was already
+	// reported for. It goes with every other type, so one mistake isn't
+	// reported over and over
+	UnknownType Type = iota
+	BooleanType
+	IntegerType
+	NumericType
+	TextType
+	DateType
+	TimestampType
+	BlobType
+)
+
+var typeNames = []string{"unknown", "boolean", "integer", "numeric", "text", "date", "timestamp", "blob"}
+
+func (t Type) String() string {
+	return typeNames[t]
+}
+
+// columnTypes maps every column type to the type of its values
+var columnTypes = map[lexer.Keyword]Type{
+	lexer.IntKeyword:       IntegerType,
+	lexer.BigintKeyword:    IntegerType,
+	lexer.RealKeyword:      NumericType,
+	lexer.DoubleKeyword:    NumericType,
+	lexer.NumericKeyword:   NumericType,
+	lexer.TextKeyword:      TextType,
+	lexer.VarcharKeyword:   TextType,
+	lexer.BooleanKeyword:   BooleanType,
+	lexer.DateKeyword:      DateType,
+	lexer.TimestampKeyword: TimestampType,
+	lexer.BlobKeyword:      BlobType,
+}
+
+// TypeOf is the type of the values of a column declared as dt
+func TypeOf(dt parser.DataType) Type {
+	return columnTypes[lexer.Keyword(dt.Name.Value)]
+}
+
+func isNumeric(t Type) bool {
+	return t == IntegerType || t == NumericType
+}
+
+func isTemporal(t Type) bool {
+	return t == DateType || t == TimestampType
+}
+
+// commonType is the type values of a and b are both converted to when
+// they're compared or combined, widening integers to numerics and dates
+// to timestamps
+func commonType(a, b Type) (Type, bool) {
+	switch {
+	case a == b:
+		return a, true
+	case a == UnknownType:
+		return b, true
+	case b == UnknownType:
+		return a, true
+	case isNumeric(a) && isNumeric(b):
+		return NumericType, true
+	case isTemporal(a) && isTemporal(b):
+		return TimestampType, true
+	}
+
+	return UnknownType, false
+}
+
+type Column struct {
+	Name string
+	Type Type
+}
+
+type Table struct {
+	Name    string
+	Columns []*Column
+	// Query is set for views, whose rows it computes. A materialized view
+	// stores them like a table until it's refreshed
+	Query        *parser.SelectStatement
+	Materialized bool
+	// Triggers run on the changes to the table's rows
+	Triggers []*parser.CreateTriggerStatement
+}
+
+// Kind is what the table is called in messages
+func (t *Table) Kind() string {
+	switch {
+	case t.Materialized:
+		return "Materialized view"
+	case t.Query != nil:
+		return "View"
+	}
+
+	return "Table"
+}
+
+// Trigger finds the trigger called name
+func (t *Table) Trigger(name string) (*parser.CreateTriggerStatement, bool) {
+	for _, trigger := range t.Triggers {
+		if trigger.Name.Value == name {
+			return trigger, true
+		}
+	}
+
+	return nil, false
+}
+
+// Column finds the column called name
+func (t *Table) Column(name string) (*Column, bool) {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+
+	return nil, false
+}
+
+// Schema is a namespace of tables. Every attached database is one
+type Schema struct {
+	Name string
+	// Attached is set for the schemas of attached database files, whose
+	// tables aren't known until they're read
+	Attached bool
+	tables   map[string]*Table
+}
+
+func NewSchema(name string) *Schema {
+	return &Schema{Name: name, tables: map[string]*Table{}}
+}
+
+func (s *Schema) Table(name string) (*Table, bool) {
+	t, ok := s.tables[name]
+
+	return t, ok
+}
+
+// AddTable adds t, replacing any table of the same name
+func (s *Schema) AddTable(t *Table) {
+	s.tables[t.Name] = t
+}
+
+func (s *Schema) DropTable(name string) {
+	delete(s.tables, name)
+}
+
+// Empty reports whether the schema has no tables it knows of
+func (s *Schema) Empty() bool {
+	return len(s.tables) == 0
+}
+
+// DefaultSchema holds the tables whose names aren't qualified
+const DefaultSchema = "main"
+
+// Catalog holds the schemas statements are bound against
+type Catalog struct {
+	schemas map[string]*Schema
+}
+
+// NewCatalog returns a catalog with just the empty default schema
+func NewCatalog() *Catalog {
+	c := &Catalog{schemas: map[string]*Schema{}}
+	c.AddSchema(NewSchema(DefaultSchema))
+
+	return c
+}
+
+func (c *Catalog) Schema(name string) (*Schema, bool) {
+	s, ok := c.schemas[name]
+
+	return s, ok
+}
+
+func (c *Catalog) AddSchema(s *Schema) {
+	c.schemas[s.Name] = s
+}
+
+func (c *Catalog) DropSchema(name string) {
+	delete(c.schemas, name)
+}
@@ -0,0 +1,75 @@
+package binder
+
+// function describes a built-in: how many arguments it takes, the types
+// they must have and the type it returns
+type function struct {
+	min int
+	// max is the most arguments the function takes, or -1 for no limit
+	max int
+	// params holds the type of each argument, the last one repeating for
+	// any further arguments. UnknownType accepts every type, and
+	// NumericType accepts integers too
+	params []Type
+	// result is what the call evaluates to. UnknownType makes it the type
+	// of the first argument, as for MAX
+	result Type
+	// matched arguments are combined into one type, which is the result,
+	// as for COALESCE
+	matched bool
+	// compared arguments must be comparable with each other, as the two of
+	// NULLIF
+	compared bool
+	// star functions take a * for their argument, as COUNT(*) does
+	star bool
+	// window functions can only be called with OVER
+	window bool
+}
+
+// functions are the built-ins calls are bound against, by lower case name.
+// EXTRACT is bound apart, as its first argument names a field
+var functions = map[string]function{
+	// Aggregates, which can also be used as window functions
+	"count": {min: 1, max: 1, params: []Type{UnknownType}, result: IntegerType, star: true},
+	"sum":   {min: 1, max: 1, params: []Type{NumericType}},
+	"avg":   {min: 1, max: 1, params: []Type{NumericType}, result: NumericType},
+	"min":   {min: 1, max: 1, params: []Type{UnknownType}},
+	"max":   {min: 1, max: 1, params: []Type{UnknownType}},
+
+	// Window functions
+	"row_number":  {result: IntegerType, window: true},
+	"rank":        {result: IntegerType, window: true},
+	"dense_rank":  {result: IntegerType, window: true},
+	"lag":         {min: 1, max: 3, params: []Type{UnknownType, IntegerType, UnknownType}, window: true},
+	"lead":        {min: 1, max: 3, params: []Type{UnknownType, IntegerType, UnknownType}, window: true},
+	"first_value": {min: 1, max: 1, params: []Type{UnknownType}, window: true},
+
+	"coalesce": {min: 1, max: -1, params: []Type{UnknownType}, matched: true},
+	"nullif":   {min: 2, max: 2, params: []Type{UnknownType}, compared: true},
+
+	// Strings
+	"lower":   {min: 1, max: 1, params: []Type{TextType}, result: TextType},
+	"upper":   {min: 1, max: 1, params: []Type{TextType}, result: TextType},
+	"length":  {min: 1, max: 1, params: []Type{TextType}, result: IntegerType},
+	"substr":  {min: 2, max: 3, params: []Type{TextType, IntegerType}, result: TextType},
+	"trim":    {min: 1, max: 2, params: []Type{TextType}, result: TextType},
+	"replace": {min: 3, max: 3, params: []Type{TextType}, result: TextType},
+
+	// Math
+	"abs":   {min: 1, max: 1, params: []Type{NumericType}},
+	"round": {min: 1, max: 2, params: []Type{NumericType, IntegerType}},
+	"floor": {min: 1, max: 1, params: []Type{NumericType}},
+	"mod":   {min: 2, max: 2, params: []Type{NumericType}, matched: true},
+
+	// Dates
+	"now":        {result: TimestampType},
+	"date_trunc": {min: 2, max: 2, params: []Type{TextType, TimestampType}, result: TimestampType},
+}
+
+// param is the type the i-th argument must have
+func (f function) param(i int) Type {
+	if i < len(f.params) {
+		return f.params[i]
+	}
+
+	return f.params[len(f.params)-1]
+}
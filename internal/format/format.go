@@ -0,0 +1,697 @@
+package format
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Jadiscke/myown-sql/internal/lexer"
+	"github.com/Jadiscke/myown-sql/internal/parser"
+)
+
+type KeywordCase uint
+
+const (
+	UpperCase KeywordCase = iota
+	LowerCase
+)
+
+type Options struct {
+	KeywordCase KeywordCase
+	// Indent is the number of spaces list items are indented by when a
+	// list is broken over several lines
+	Indent int
+	// Width is the line length past which a list is broken one item per
+	// line. Zero never breaks lists
+	Width int
+}
+
+func DefaultOptions() Options {
+	return Options{
+		KeywordCase: UpperCase,
+		Indent:      2,
+		Width:       80,
+	}
+}
+
+// Format parses source and prints it back as canonical SQL. Comments are
+// kept: those before or inside a statement are printed on their own lines
+// ahead of it, and one on the line a statement ends on stays after it
+func Format(source string, options Options) (string, error) {
+	a, err := parser.Parse(source)
+
+	if err != nil {
+		return "", err
+	}
+
+	tokens, err := lexer.Lex(source)
+
+	if err != nil {
+		return "", err
+	}
+
+	comments, err := lexer.Comments(source)
+
+	if err != nil {
+		return "", err
+	}
+
+	ends := statementEnds(tokens)
+
+	if len(ends) != len(a.Statements) {
+		return "", errors.New("Statements don't match the source")
+	}
+
+	p := printer{options: options}
+
+	var b strings.Builder
+
+	c := 0
+
+	for i, stmt := range a.Statements {
+		for c < len(comments) && comments[c].Span.Start < ends[i].Span.End {
+			b.WriteString(comments[c].Raw + "\n")
+			c++
+		}
+
+		b.WriteString(p.statement(stmt) + ";")
+
+		for c < len(comments) && comments[c].Loc.Line == ends[i].Loc.Line {
+			b.WriteString(" " + comments[c].Raw)
+			c++
+		}
+
+		b.WriteString("\n")
+	}
+
+	for ; c < len(comments); c++ {
+		b.WriteString(comments[c].Raw + "\n")
+	}
+
+	return b.String(), nil
+}
+
+// FormatAst prints every statement of the AST as canonical SQL
+func FormatAst(a *parser.Ast, options Options) string {
+	p := printer{options: options}
+
+	var b strings.Builder
+
+	for _, stmt := range a.Statements {
+		b.WriteString(p.statement(stmt) + ";\n")
+	}
+
+	return b.String()
+}
+
+// statementEnds finds the last token of each statement, its semicolon when
+// it has one
+func statementEnds(tokens []*lexer.Token) []*lexer.Token {
+	var ends []*lexer.Token
+
+	var s parser.Splitter
+	empty := true
+
+	for _, t := range tokens {
+		if s.Ends(t) {
+			if !empty {
+				ends = append(ends, t)
+			}
+
+			empty = true
+
+			continue
+		}
+
+		empty = false
+	}
+
+	if !empty {
+		ends = append(ends, tokens[len(tokens)-1])
+	}
+
+	return ends
+}
+
+type printer struct {
+	options Options
+}
+
+func (p printer) keyword(kw lexer.Keyword) string {
+	if p.options.KeywordCase == LowerCase {
+		return string(kw)
+	}
+
+	return strings.ToUpper(string(kw))
+}
+
+// keywords prints several keywords separated by spaces
+func (p printer) keywords(kws ...lexer.Keyword) string {
+	var words []string
+
+	for _, kw := range kws {
+		words = append(words, p.keyword(kw))
+	}
+
+	return strings.Join(words, " ")
+}
+
+func (p printer) token(t *lexer.Token) string {
+	switch t.Kind {
+	case lexer.KeywordKind, lexer.BoolKind, lexer.NullKind:
+		return p.keyword(lexer.Keyword(t.Value))
+	case lexer.QuotedIdentifierKind:
+		return `"` + strings.ReplaceAll(t.Value, `"`, `""`) + `"`
+	case lexer.StringKind:
+		// The raw text keeps E'' escapes and $$ quoting
+		if t.Raw != "" {
+			return t.Raw
+		}
+
+		return "'" + t.Value + "'"
+	}
+
+	return t.Value
+}
+
+func (p printer) tokens(ts []*lexer.Token) []string {
+	var out []string
+
+	for _, t := range ts {
+		out = append(out, p.token(t))
+	}
+
+	return out
+}
+
+// list prints head followed by the items, breaking them one per line when
+// they don't fit the width
+func (p printer) list(head string, items []string, inline bool) string {
+	line := head + " " + strings.Join(items, ", ")
+
+	if inline || !p.tooWide(line) {
+		return line
+	}
+
+	indent := strings.Repeat(" ", p.options.Indent)
+
+	return head + "\n" + indent + strings.Join(items, ",\n"+indent)
+}
+
+// parenList prints head followed by the items in parens, breaking them one
+// per line when they don't fit the width
+func (p printer) parenList(head string, items []string) string {
+	line := head + " (" + strings.Join(items, ", ") + ")"
+
+	if !p.tooWide(line) {
+		return line
+	}
+
+	indent := strings.Repeat(" ", p.options.Indent)
+
+	return head + " (\n" + indent + strings.Join(items, ",\n"+indent) + "\n)"
+}
+
+func (p printer) tooWide(line string) bool {
+	return p.options.Width > 0 && utf8.RuneCountInString(line) > p.options.Width
+}
+
+func (p printer) statement(stmt *parser.Statement) string {
+	switch stmt.Kind {
+	case parser.SelectKind:
+		return p.query(stmt.SelectStatement, false)
+	case parser.InsertKind:
+		return p.insert(stmt.InsertStatement)
+	case parser.CreateTableKind:
+		return p.createTable(stmt.CreateTableStatement)
+	case parser.AlterTableKind:
+		return p.alterTable(stmt.AlterTableStatement)
+	case parser.CreateSchemaKind:
+		return p.keywords(lexer.CreateKeyword, lexer.SchemaKeyword) + " " + p.token(&stmt.CreateSchemaStatement.Name)
+	case parser.DropSchemaKind:
+		return p.keywords(lexer.DropKeyword, lexer.SchemaKeyword) + " " + p.token(&stmt.DropSchemaStatement.Name)
+	case parser.AttachKind:
+		attach := stmt.AttachStatement
+
+		return p.keywords(lexer.AttachKeyword, lexer.DatabaseKeyword) + " " + p.token(&attach.File) + " " + p.keyword(lexer.AsKeyord) + " " + p.token(&attach.Name)
+	case parser.CreateViewKind:
+		return p.createView(stmt.CreateViewStatement)
+	case parser.RefreshViewKind:
+		refresh := stmt.RefreshViewStatement
+
+		return p.keywords(lexer.RefreshKeyword, lexer.MaterializedKeyword, lexer.ViewKeyword) + " " + p.tableName(refresh.Schema, &refresh.Name)
+	case parser.DropViewKind:
+		drop := stmt.DropViewStatement
+		head := p.keyword(lexer.DropKeyword)
+
+		if drop.Materialized {
+			head += " " + p.keyword(lexer.MaterializedKeyword)
+		}
+
+		return head + " " + p.keyword(lexer.ViewKeyword) + " " + p.tableName(drop.Schema, &drop.Name)
+	case parser.CreateTriggerKind:
+		return p.createTrigger(stmt.CreateTriggerStatement)
+	}
+
+	return ""
+}
+
+func (p printer) with(w *parser.WithClause, inline bool) string {
+	head := p.keyword(lexer.WithKeyword)
+
+	if w.Recursive {
+		head += " " + p.keyword(lexer.RecursiveKeyword)
+	}
+
+	var ctes []string
+
+	for _, cte := range w.Ctes {
+		s := p.token(&cte.Name)
+
+		if len(cte.Columns) > 0 {
+			s += " (" + strings.Join(p.tokens(cte.Columns), ", ") + ")"
+		}
+
+		ctes = append(ctes, s+" "+p.keyword(lexer.AsKeyord)+" ("+p.query(cte.Query, true)+")")
+	}
+
+	return p.list(head, ctes, inline)
+}
+
+// query prints a SELECT with a clause per line, or on a single line when
+// inline, as it is for subqueries
+func (p printer) query(s *parser.SelectStatement, inline bool) string {
+	var clauses []string
+
+	if s.With != nil {
+		clauses = append(clauses, p.with(s.With, inline))
+	}
+
+	if s.SetOperation != nil {
+		op := p.token(&s.SetOperation.Op)
+
+		if s.SetOperation.All {
+			op += " " + p.keyword(lexer.AllKeyword)
+		}
+
+		// Set operators associate to the left, so a right operand of the
+		// same power needs parens
+		bp := s.BindingPower()
+
+		clauses = append(clauses,
+			p.setOperand(s.SetOperation.Left, bp, inline),
+			op,
+			p.setOperand(s.SetOperation.Right, bp+1, inline),
+		)
+	} else {
+		head := p.keyword(lexer.SelectKeyword)
+
+		if s.Distinct {
+			head += " " + p.keyword(lexer.DistinctKeyword)
+
+			if len(s.DistinctOn) > 0 {
+				head += " " + p.keyword(lexer.OnKeyword) + " (" + strings.Join(p.expressions(s.DistinctOn), ", ") + ")"
+			}
+		}
+
+		clauses = append(clauses, p.list(head, p.expressions(s.Item), inline))
+
+		if s.From != nil {
+			clauses = append(clauses, p.keyword(lexer.FromKeyword)+" "+p.fromItem(s.From))
+		}
+
+		if s.Where != nil {
+			clauses = append(clauses, p.keyword(lexer.WhereKeyword)+" "+p.expression(s.Where))
+		}
+	}
+
+	if len(s.OrderBy) > 0 {
+		clauses = append(clauses, p.list(p.keywords(lexer.OrderKeyword, lexer.ByKeyword), p.orderBy(s.OrderBy), inline))
+	}
+
+	if s.Limit != nil {
+		clauses = append(clauses, p.keyword(lexer.LimitKeyword)+" "+p.expression(s.Limit))
+	}
+
+	if s.Offset != nil {
+		clauses = append(clauses, p.keyword(lexer.OffsetKeyword)+" "+p.expression(s.Offset))
+	}
+
+	if inline {
+		return strings.Join(clauses, " ")
+	}
+
+	return strings.Join(clauses, "\n")
+}
+
+func (p printer) fromItem(item *parser.FromItem) string {
+	var s string
+
+	if item.Kind == parser.SubqueryFromKind {
+		s = "(" + p.query(item.Subquery, true) + ")"
+	} else {
+		s = p.tableName(item.Schema, item.Table)
+	}
+
+	if item.Alias != nil {
+		s += " " + p.keyword(lexer.AsKeyord) + " " + p.token(item.Alias)
+	}
+
+	return s
+}
+
+func (p printer) tableName(schema, name *lexer.Token) string {
+	if schema != nil {
+		return p.token(schema) + "." + p.token(name)
+	}
+
+	return p.token(name)
+}
+
+func (p printer) orderBy(items []*parser.OrderByItem) []string {
+	var out []string
+
+	for _, item := range items {
+		s := p.expression(item.Exp)
+
+		if item.Desc {
+			s += " " + p.keyword(lexer.DescKeyword)
+		}
+
+		out = append(out, s)
+	}
+
+	return out
+}
+
+func (p printer) insert(s *parser.InsertStatement) string {
+	var clauses []string
+
+	if s.With != nil {
+		clauses = append(clauses, p.with(s.With, false))
+	}
+
+	head := p.keywords(lexer.InsertKeyword, lexer.IntoKeyword) + " " + p.tableName(s.Schema, &s.Table) + " " + p.keyword(lexer.ValuesKeyword)
+
+	clauses = append(clauses, p.parenList(head, p.expressions(*s.Values)))
+
+	return strings.Join(clauses, "\n")
+}
+
+func (p printer) datatype(dt *parser.DataType) string {
+	s := p.token(&dt.Name)
+
+	if len(dt.Params) > 0 {
+		s += "(" + strings.Join(p.tokens(dt.Params), ", ") + ")"
+	}
+
+	return s
+}
+
+func (p printer) columnDefinition(col *parser.ColumnDefinition) string {
+	s := p.token(&col.Name) + " " + p.datatype(&col.Datatype)
+
+	if col.Default != nil {
+		s += " " + p.keyword(lexer.DefaultKeyword) + " " + p.expression(col.Default)
+	}
+
+	return s
+}
+
+func (p printer) createTable(s *parser.CreateTableStatement) string {
+	var cols []string
+
+	for _, col := range *s.Cols {
+		cols = append(cols, p.columnDefinition(col))
+	}
+
+	return p.parenList(p.keywords(lexer.CreateKeyword, lexer.TableKeyword)+" "+p.tableName(s.Schema, &s.Name), cols)
+}
+
+func (p printer) createView(s *parser.CreateViewStatement) string {
+	head := p.keyword(lexer.CreateKeyword)
+
+	if s.Materialized {
+		head += " " + p.keyword(lexer.MaterializedKeyword)
+	}
+
+	head += " " + p.keyword(lexer.ViewKeyword) + " " + p.tableName(s.Schema, &s.Name)
+
+	if s.Columns != nil {
+		head += " (" + strings.Join(p.tokens(s.Columns), ", ") + ")"
+	}
+
+	return head + " " + p.keyword(lexer.AsKeyord) + "\n" + p.query(s.Query, false)
+}
+
+// createTrigger prints the body a statement per line, each indented
+func (p printer) createTrigger(s *parser.CreateTriggerStatement) string {
+	head := p.keywords(lexer.CreateKeyword, lexer.TriggerKeyword) + " " + p.token(&s.Name) + " " +
+		p.token(&s.Timing) + " " + p.token(&s.Event) + " " + p.keyword(lexer.OnKeyword) + " " +
+		p.tableName(s.Schema, &s.Table) + " " + p.keywords(lexer.ForKeyword, lexer.EachKeyword, lexer.RowKeyword)
+
+	if s.When != nil {
+		head += " " + p.keyword(lexer.WhenKeyword) + " " + p.expression(s.When)
+	}
+
+	indent := strings.Repeat(" ", p.options.Indent)
+
+	var b strings.Builder
+
+	b.WriteString(head + "\n" + p.keyword(lexer.BeginKeyword) + "\n")
+
+	for _, stmt := range s.Body {
+		b.WriteString(indent + strings.ReplaceAll(p.statement(stmt), "\n", "\n"+indent) + ";\n")
+	}
+
+	return b.String() + p.keyword(lexer.EndKeyword)
+}
+
+func (p printer) alterTable(s *parser.AlterTableStatement) string {
+	head := p.keywords(lexer.AlterKeyword, lexer.TableKeyword) + " " + p.tableName(s.Schema, &s.Name) + " "
+
+	switch s.Kind {
+	case parser.AddColumnKind:
+		return head + p.keywords(lexer.AddKeyword, lexer.ColumnKeyword) + " " + p.columnDefinition(s.AddColumn)
+	case parser.DropColumnKind:
+		return head + p.keywords(lexer.DropKeyword, lexer.ColumnKeyword) + " " + p.token(&s.Column)
+	case parser.RenameColumnKind:
+		return head + p.keywords(lexer.RenameKeyword, lexer.ColumnKeyword) + " " + p.token(&s.Column) + " " + p.keyword(lexer.ToKeyword) + " " + p.token(&s.NewName)
+	}
+
+	return head + p.keywords(lexer.RenameKeyword, lexer.ToKeyword) + " " + p.token(&s.NewName)
+}
+
+func (p printer) expressions(exps []*parser.Expression) []string {
+	var out []string
+
+	for _, e := range exps {
+		out = append(out, p.expression(e))
+	}
+
+	return out
+}
+
+// operand prints e, in parens when it binds looser than bp
+func (p printer) operand(e *parser.Expression, bp uint) string {
+	if e.BindingPower() < bp {
+		return "(" + p.expression(e) + ")"
+	}
+
+	return p.expression(e)
+}
+
+func (p printer) expression(e *parser.Expression) string {
+	switch e.Kind {
+	case parser.LiteralKind:
+		if e.Table != nil {
+			return p.token(e.Table) + "." + p.token(e.Literal)
+		}
+
+		return p.token(e.Literal)
+	case parser.BinaryKind:
+		return p.binary(e)
+	case parser.UnaryKind:
+		if e.Unary.Operand.Kind == parser.SubqueryKind {
+			return p.token(&e.Unary.Op) + " " + p.expression(&e.Unary.Operand)
+		}
+
+		return p.token(&e.Unary.Op) + " " + p.operand(&e.Unary.Operand, e.BindingPower())
+	case parser.CallKind:
+		return p.call(e.Call)
+	case parser.SubqueryKind:
+		return "(" + p.query(e.Subquery, true) + ")"
+	case parser.CaseKind:
+		return p.caseExpression(e.Case)
+	case parser.CastKind:
+		return p.cast(e)
+	case parser.BetweenKind:
+		return p.between(e)
+	case parser.ListKind:
+		return "(" + strings.Join(p.expressions(e.List), ", ") + ")"
+	}
+
+	return ""
+}
+
+// setOperand prints an operand of a set operator, in parens when it binds
+// looser than bp or has clauses that would otherwise apply to the whole
+// compound query
+func (p printer) setOperand(s *parser.SelectStatement, bp uint, inline bool) string {
+	if s.With != nil || len(s.OrderBy) > 0 || s.Limit != nil || s.Offset != nil || s.BindingPower() < bp {
+		return "(" + p.query(s, inline) + ")"
+	}
+
+	return p.query(s, inline)
+}
+
+func (p printer) binary(e *parser.Expression) string {
+	b := e.Binary
+	bp := e.BindingPower()
+
+	op := p.token(&b.Op)
+
+	// IS [NOT] DISTINCT FROM is held with DISTINCT as its operator
+	if b.Op.Kind == lexer.KeywordKind && b.Op.Value == string(lexer.DistinctKeyword) {
+		op = p.keyword(lexer.IsKeyword) + " "
+
+		if b.Not {
+			op += p.keyword(lexer.NotKeyword) + " "
+		}
+
+		op += p.keywords(lexer.DistinctKeyword, lexer.FromKeyword)
+	} else if b.Not {
+		if b.Op.Value == string(lexer.IsKeyword) {
+			op += " " + p.keyword(lexer.NotKeyword)
+		} else {
+			op = p.keyword(lexer.NotKeyword) + " " + op
+		}
+	}
+
+	// Operators associate to the left, so a right operand of the same
+	// power needs parens
+	s := p.operand(&b.A, bp) + " " + op + " " + p.operand(&b.B, bp+1)
+
+	if b.Escape != nil {
+		s += " " + p.keyword(lexer.EscapeKeyword) + " " + p.operand(b.Escape, bp+1)
+	}
+
+	return s
+}
+
+func (p printer) between(e *parser.Expression) string {
+	b := e.Between
+	bp := e.BindingPower()
+
+	op := p.keyword(lexer.BetweenKeyword)
+
+	if b.Not {
+		op = p.keyword(lexer.NotKeyword) + " " + op
+	}
+
+	return p.operand(&b.Operand, bp) + " " + op + " " + p.operand(&b.Low, bp+1) + " " + p.keyword(lexer.AndKeyword) + " " + p.operand(&b.High, bp+1)
+}
+
+func (p printer) cast(e *parser.Expression) string {
+	c := e.Cast
+
+	if c.Op.Kind == lexer.SymbolKind {
+		return p.operand(&c.Operand, e.BindingPower()) + string(lexer.CastSymbol) + p.datatype(&c.Type)
+	}
+
+	return p.keyword(lexer.CastKeyword) + "(" + p.expression(&c.Operand) + " " + p.keyword(lexer.AsKeyord) + " " + p.datatype(&c.Type) + ")"
+}
+
+func (p printer) call(c *parser.CallExpression) string {
+	name := p.token(&c.Name)
+
+	// EXTRACT(field FROM source) is kept as a call on both arguments
+	if c.Name.Kind == lexer.KeywordKind && c.Name.Value == string(lexer.ExtractKeyword) {
+		return name + "(" + p.expression(c.Args[0]) + " " + p.keyword(lexer.FromKeyword) + " " + p.expression(c.Args[1]) + ")"
+	}
+
+	args := strings.Join(p.expressions(c.Args), ", ")
+
+	if c.Distinct {
+		args = p.keyword(lexer.DistinctKeyword) + " " + args
+	}
+
+	s := name + "(" + args + ")"
+
+	if c.Over != nil {
+		s += " " + p.keyword(lexer.OverKeyword) + " (" + p.window(c.Over) + ")"
+	}
+
+	return s
+}
+
+func (p printer) window(w *parser.WindowDefinition) string {
+	var parts []string
+
+	if len(w.PartitionBy) > 0 {
+		parts = append(parts, p.keywords(lexer.PartitionKeyword, lexer.ByKeyword)+" "+strings.Join(p.expressions(w.PartitionBy), ", "))
+	}
+
+	if len(w.OrderBy) > 0 {
+		parts = append(parts, p.keywords(lexer.OrderKeyword, lexer.ByKeyword)+" "+strings.Join(p.orderBy(w.OrderBy), ", "))
+	}
+
+	if w.Frame != nil {
+		frame := p.token(&w.Frame.Unit) + " "
+
+		if w.Frame.End != nil {
+			frame += p.keyword(lexer.BetweenKeyword) + " " + p.frameBound(&w.Frame.Start) + " " + p.keyword(lexer.AndKeyword) + " " + p.frameBound(w.Frame.End)
+		} else {
+			frame += p.frameBound(&w.Frame.Start)
+		}
+
+		parts = append(parts, frame)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func (p printer) frameBound(b *parser.FrameBound) string {
+	switch b.Kind {
+	case parser.UnboundedPrecedingKind:
+		return p.keywords(lexer.UnboundedKeyword, lexer.PrecedingKeyword)
+	case parser.UnboundedFollowingKind:
+		return p.keywords(lexer.UnboundedKeyword, lexer.FollowingKeyword)
+	case parser.CurrentRowKind:
+		return p.keywords(lexer.CurrentKeyword, lexer.RowKeyword)
+	case parser.PrecedingKind:
+		return p.frameOffset(b.Offset) + " " + p.keyword(lexer.PrecedingKeyword)
+	}
+
+	return p.frameOffset(b.Offset) + " " + p.keyword(lexer.FollowingKeyword)
+}
+
+// frameOffset prints the n of n PRECEDING, in parens unless it is a literal
+// so it can't swallow the AND of BETWEEN ... AND ...
+func (p printer) frameOffset(e *parser.Expression) string {
+	if e.Kind == parser.LiteralKind {
+		return p.expression(e)
+	}
+
+	return "(" + p.expression(e) + ")"
+}
+
+func (p printer) caseExpression(c *parser.CaseExpression) string {
+	parts := []string{p.keyword(lexer.CaseKeyword)}
+
+	if c.Operand != nil {
+		parts = append(parts, p.expression(c.Operand))
+	}
+
+	for _, w := range c.Whens {
+		parts = append(parts, p.keyword(lexer.WhenKeyword), p.expression(&w.When), p.keyword(lexer.ThenKeyword), p.expression(&w.Then))
+	}
+
+	if c.Else != nil {
+		parts = append(parts, p.keyword(lexer.ElseKeyword), p.expression(c.Else))
+	}
+
+	parts = append(parts, p.keyword(lexer.EndKeyword))
+
+	return strings.Join(parts, " ")
+}
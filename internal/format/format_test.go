@@ -0,0 +1,249 @@
+package format
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/Jadiscke/myown-sql/internal/lexer"
+	"github.com/Jadiscke/myown-sql/internal/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		options  Options
+		expected string
+	}{
+		{
+			input:    "select a,b from t where a=1 and not b order by a desc limit 10",
+			options:  DefaultOptions(),
+			expected: "SELECT a, b\nFROM t\nWHERE a = 1 AND NOT b\nORDER BY a DESC\nLIMIT 10;\n",
+		},
+		{
+			input:    "SELECT A FROM T WHERE (a OR b) AND c",
+			options:  Options{KeywordCase: LowerCase},
+			expected: "select a\nfrom t\nwhere (a or b) and c;\n",
+		},
+		{
+			input:    "select first_name, last_name, email from users",
+			options:  Options{Indent: 4, Width: 20},
+			expected: "SELECT\n    first_name,\n    last_name,\n    email\nFROM users;\n",
+		},
+		{
+			input:    "create table users (id int, name varchar(255) default 'x', price numeric(10,2))",
+			options:  Options{Indent: 2, Width: 40},
+			expected: "CREATE TABLE users (\n  id INT,\n  name VARCHAR(255) DEFAULT 'x',\n  price NUMERIC(10, 2)\n);\n",
+		},
+		{
+			input:    "insert into t values (1, E'a\\'b', $$c$$, \"Quoted\")",
+			options:  DefaultOptions(),
+			expected: "INSERT INTO t VALUES (1, E'a\\'b', $$c$$, \"Quoted\");\n",
+		},
+		{
+			input:    "-- users\nselect a /* inline */ from t; -- trailing\n\n/* between */ alter table t rename to u;\n-- last",
+			options:  DefaultOptions(),
+			expected: "-- users\n/* inline */\nSELECT a\nFROM t; -- trailing\n/* between */\nALTER TABLE t RENAME TO u;\n-- last\n",
+		},
+		{
+			input:    "select a from t union all select b from u order by 1",
+			options:  DefaultOptions(),
+			expected: "SELECT a\nFROM t\nUNION ALL\nSELECT b\nFROM u\nORDER BY 1;\n",
+		},
+		{
+			input:    "create view v (a) as select a from t where b; drop materialized view v",
+			options:  DefaultOptions(),
+			expected: "CREATE VIEW v (a) AS\nSELECT a\nFROM t\nWHERE b;\nDROP MATERIALIZED VIEW v;\n",
+		},
+		{
+			input:    "create trigger t before insert on u for each row when new.a > 0 begin insert into log values (new.a); select a from u where a = new.a; end; select b from t",
+			options:  Options{KeywordCase: LowerCase, Indent: 4},
+			expected: "create trigger t before insert on u for each row when new.a > 0\nbegin\n    insert into log values (new.a);\n    select a\n    from u\n    where a = new.a;\nend;\nselect b\nfrom t;\n",
+		},
+		{
+			input:    "select (a = b) = c, a = (b = c), -(1)::int, x not between 1 and 2 from t",
+			options:  DefaultOptions(),
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		out, err := Format(test.input, test.options)
+
+		if test.expected == "" {
+			assert.NotNil(t, err, test.input)
+			continue
+		}
+
+		assert.Nil(t, err, test.input)
+		assert.Equal(t, test.expected, out, test.input)
+	}
+}
+
+var corpus = []string{
+	"SELECT a, b FROM t WHERE a >= 1 AND b <> 'x' OR NOT c",
+	"SELECT DISTINCT ON (a, b) a, b, c FROM t AS x ORDER BY a, b DESC",
+	"SELECT count(DISTINCT a), coalesce(a, b, 1), nullif(a, 0) FROM t",
+	"SELECT *, x.*, count(*) OVER (PARTITION BY a) FROM t AS x",
+	"SELECT 1, now() ORDER BY 1",
+	"SELECT row_number() OVER (PARTITION BY a ORDER BY b DESC ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) FROM t",
+	"SELECT sum(a) OVER (ORDER BY b RANGE BETWEEN 1 PRECEDING AND 2 FOLLOWING), max(a) OVER (ROWS UNBOUNDED PRECEDING) FROM t",
+	"SELECT a FROM t WHERE a IN (1, 2, 3) AND b NOT IN (SELECT b FROM u) AND EXISTS (SELECT 1 FROM v)",
+	"SELECT a FROM t WHERE a NOT LIKE 'x!%' ESCAPE '!' AND b ILIKE 'y%' AND c BETWEEN 1 AND 2 AND d IS NOT NULL",
+	"SELECT CASE a WHEN 1 THEN 'one' ELSE 'many' END, CASE WHEN a > 1 THEN b END FROM t",
+	"SELECT CAST(a AS NUMERIC(10, 2)), b::TEXT, (a || b)::VARCHAR(3), EXTRACT(year FROM d) FROM t",
+	"SELECT date_trunc('month', d) FROM t WHERE d IS NULL OR (a = b) IS TRUE",
+	"SELECT a IS DISTINCT FROM b FROM t WHERE a IS NOT DISTINCT FROM b AND c OR (a IS DISTINCT FROM b) IS TRUE",
+	"WITH RECURSIVE r (n) AS (SELECT n FROM base UNION SELECT n FROM r) SELECT n FROM r LIMIT 5 OFFSET 2",
+	"SELECT a FROM (SELECT a FROM t WHERE b) AS sub WHERE a = (SELECT max(a) FROM t)",
+	"SELECT a FROM t INTERSECT SELECT a FROM u EXCEPT SELECT a FROM v",
+	"SELECT 1 FROM t EXCEPT (SELECT 2 FROM u EXCEPT SELECT 3 FROM v)",
+	"(SELECT 1 FROM t UNION SELECT 2 FROM u) INTERSECT SELECT 3 FROM v",
+	"SELECT a FROM t INTERSECT (SELECT a FROM u UNION ALL SELECT a FROM v) ORDER BY 1",
+	"(SELECT a FROM t ORDER BY a LIMIT 1) UNION (SELECT a FROM u OFFSET 2) UNION (WITH w AS (SELECT a FROM v) SELECT a FROM w)",
+	"SELECT date, rows, \"Mixed Case\" FROM \"Table\" AS range",
+	"SELECT \"My\"\"T\".\"a\"\"\" FROM \"My\"\"T\"",
+	"SELECT t.a, \"T\".\"B\", u.date FROM t WHERE t.a = .5 AND \"t\".b",
+	"INSERT INTO t VALUES (1, 'it''s', E'tab\\t', $q$dollar$q$, TRUE, NULL, 1.5e3)",
+	"WITH src AS (SELECT a FROM t) INSERT INTO u VALUES (1)",
+	"CREATE TABLE t (id BIGINT, name VARCHAR(20) DEFAULT 'n', ok BOOLEAN DEFAULT FALSE, at TIMESTAMP, data BLOB)",
+	"ALTER TABLE t ADD COLUMN c INT DEFAULT 0",
+	"ALTER TABLE t DROP COLUMN c",
+	"ALTER TABLE t RENAME COLUMN a TO b",
+	"ALTER TABLE t RENAME TO u",
+	"CREATE SCHEMA audit",
+	"DROP SCHEMA audit",
+	"ATTACH 'archive.db' AS archive",
+	"CREATE TABLE audit.log (at TIMESTAMP)",
+	"INSERT INTO archive.users VALUES (1)",
+	"ALTER TABLE \"Audit\".log RENAME TO entries",
+	"SELECT x.a FROM archive.t AS x WHERE x.a IN (SELECT a FROM main.t)",
+	"CREATE VIEW active_users AS SELECT id, name FROM users WHERE active",
+	"CREATE MATERIALIZED VIEW audit.totals (user_id, total) AS WITH o AS (SELECT user_id, total FROM orders) SELECT user_id, sum(total) FROM o UNION SELECT 0, 0 FROM t ORDER BY 1",
+	"REFRESH MATERIALIZED VIEW audit.totals",
+	"DROP VIEW active_users",
+	"DROP MATERIALIZED VIEW audit.totals",
+	"CREATE TRIGGER log_users AFTER UPDATE ON audit.users FOR EACH ROW WHEN OLD.name <> NEW.name BEGIN INSERT INTO audit.log VALUES (OLD.id, CASE WHEN NEW.name IS NULL THEN 'x' ELSE NEW.name END); SELECT id FROM users WHERE id = NEW.id ORDER BY id; END",
+}
+
+var tokenType = reflect.TypeOf(lexer.Token{})
+
+// stripPositions zeroes where every token came from, leaving what it means
+func stripPositions(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			stripPositions(v.Elem())
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			stripPositions(v.Index(i))
+		}
+	case reflect.Struct:
+		if v.Type() == tokenType {
+			v.FieldByName("Loc").SetZero()
+			v.FieldByName("Span").SetZero()
+			v.FieldByName("Raw").SetZero()
+
+			return
+		}
+
+		for i := 0; i < v.NumField(); i++ {
+			stripPositions(v.Field(i))
+		}
+	}
+}
+
+func assertRoundTrip(t *testing.T, input string, options Options) {
+	expected, err := parser.Parse(input)
+
+	if !assert.Nil(t, err, input) {
+		return
+	}
+
+	out, err := Format(input, options)
+
+	if !assert.Nil(t, err, input) {
+		return
+	}
+
+	actual, err := parser.Parse(out)
+
+	if !assert.Nil(t, err, out) {
+		return
+	}
+
+	stripPositions(reflect.ValueOf(expected))
+	stripPositions(reflect.ValueOf(actual))
+	assert.Equal(t, expected, actual, out)
+
+	again, err := Format(out, options)
+	assert.Nil(t, err, out)
+	assert.Equal(t, out, again, "formatting is idempotent")
+}
+
+func TestFormat_roundTrip(t *testing.T) {
+	options := []Options{
+		DefaultOptions(),
+		{KeywordCase: LowerCase, Indent: 4, Width: 10},
+		{KeywordCase: UpperCase},
+	}
+
+	for _, input := range corpus {
+		for _, o := range options {
+			assertRoundTrip(t, input, o)
+		}
+	}
+}
+
+// randomExpression builds a fully parenthesized expression, so the parsed
+// tree is known regardless of precedence
+func randomExpression(r *rand.Rand, depth int) string {
+	if depth == 0 || r.Intn(4) == 0 {
+		return []string{"a", "b", "c", "1", "2.5", "'s'", "TRUE", "NULL"}[r.Intn(8)]
+	}
+
+	x := func() string { return randomExpression(r, depth-1) }
+	not := []string{"", " NOT"}[r.Intn(2)]
+
+	switch r.Intn(13) {
+	case 0:
+		op := []string{"AND", "OR", "=", "<>", "<", ">=", "||"}[r.Intn(7)]
+		return fmt.Sprintf("(%s %s %s)", x(), op, x())
+	case 1:
+		return fmt.Sprintf("(NOT %s)", x())
+	case 2:
+		return fmt.Sprintf("(%s IS%s NULL)", x(), not)
+	case 3:
+		return fmt.Sprintf("(%s%s BETWEEN %s AND %s)", x(), not, x(), x())
+	case 4:
+		return fmt.Sprintf("(%s%s IN (%s, %s))", x(), not, x(), x())
+	case 5:
+		return fmt.Sprintf("(%s%s LIKE %s)", x(), not, x())
+	case 6:
+		return fmt.Sprintf("CAST(%s AS INT)", x())
+	case 7:
+		return fmt.Sprintf("(%s)::TEXT", x())
+	case 8:
+		return fmt.Sprintf("CASE WHEN %s THEN %s ELSE %s END", x(), x(), x())
+	case 9:
+		return fmt.Sprintf("coalesce(%s, %s)", x(), x())
+	case 10:
+		return fmt.Sprintf("(%s IS%s %s)", x(), not, []string{"TRUE", "FALSE"}[r.Intn(2)])
+	case 11:
+		return fmt.Sprintf("(%s IS%s DISTINCT FROM %s)", x(), not, x())
+	}
+
+	return fmt.Sprintf("EXISTS (SELECT %s FROM t)", x())
+}
+
+func TestFormat_roundTripProperty(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 500; i++ {
+		input := fmt.Sprintf("SELECT %s FROM t WHERE %s;", randomExpression(r, 4), randomExpression(r, 4))
+		assertRoundTrip(t, input, DefaultOptions())
+	}
+}
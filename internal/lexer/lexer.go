@@ -3,6 +3,8 @@ package lexer
 import (
 	"fmt"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 type Location struct {
@@ -24,6 +26,97 @@ const (
 	IntKeyword    Keyword = "int"
 	TextKeyword   Keyword = "text"
 	WhereKeyword  Keyword = "where"
+
+	BooleanKeyword   Keyword = "boolean"
+	TrueKeyword      Keyword = "true"
+	FalseKeyword     Keyword = "false"
+	BigintKeyword    Keyword = "bigint"
+	RealKeyword      Keyword = "real"
+	DoubleKeyword    Keyword = "double"
+	NumericKeyword   Keyword = "numeric"
+	VarcharKeyword   Keyword = "varchar"
+	DateKeyword      Keyword = "date"
+	TimestampKeyword Keyword = "timestamp"
+	BlobKeyword      Keyword = "blob"
+
+	NullKeyword     Keyword = "null"
+	IsKeyword       Keyword = "is"
+	NotKeyword      Keyword = "not"
+	AndKeyword      Keyword = "and"
+	OrKeyword       Keyword = "or"
+	DistinctKeyword Keyword = "distinct"
+	CoalesceKeyword Keyword = "coalesce"
+	NullifKeyword   Keyword = "nullif"
+
+	AlterKeyword   Keyword = "alter"
+	AddKeyword     Keyword = "add"
+	DropKeyword    Keyword = "drop"
+	RenameKeyword  Keyword = "rename"
+	ColumnKeyword  Keyword = "column"
+	ToKeyword      Keyword = "to"
+	DefaultKeyword Keyword = "default"
+
+	InKeyword     Keyword = "in"
+	ExistsKeyword Keyword = "exists"
+
+	WithKeyword      Keyword = "with"
+	RecursiveKeyword Keyword = "recursive"
+
+	UnionKeyword     Keyword = "union"
+	IntersectKeyword Keyword = "intersect"
+	ExceptKeyword    Keyword = "except"
+	AllKeyword       Keyword = "all"
+	OrderKeyword     Keyword = "order"
+	ByKeyword        Keyword = "by"
+	AscKeyword       Keyword = "asc"
+	DescKeyword      Keyword = "desc"
+	LimitKeyword     Keyword = "limit"
+	OffsetKeyword    Keyword = "offset"
+
+	OverKeyword      Keyword = "over"
+	PartitionKeyword Keyword = "partition"
+	RowsKeyword      Keyword = "rows"
+	RangeKeyword     Keyword = "range"
+	BetweenKeyword   Keyword = "between"
+	UnboundedKeyword Keyword = "unbounded"
+	PrecedingKeyword Keyword = "preceding"
+	FollowingKeyword Keyword = "following"
+	CurrentKeyword   Keyword = "current"
+	RowKeyword       Keyword = "row"
+	RowNumberKeyword Keyword = "row_number"
+
+	OnKeyword Keyword = "on"
+
+	ExtractKeyword   Keyword = "extract"
+	DateTruncKeyword Keyword = "date_trunc"
+
+	CaseKeyword Keyword = "case"
+	WhenKeyword Keyword = "when"
+	ThenKeyword Keyword = "then"
+	ElseKeyword Keyword = "else"
+	EndKeyword  Keyword = "end"
+	CastKeyword Keyword = "cast"
+
+	LikeKeyword   Keyword = "like"
+	IlikeKeyword  Keyword = "ilike"
+	EscapeKeyword Keyword = "escape"
+
+	SchemaKeyword   Keyword = "schema"
+	AttachKeyword   Keyword = "attach"
+	DatabaseKeyword Keyword = "database"
+
+	ViewKeyword         Keyword = "view"
+	MaterializedKeyword Keyword = "materialized"
+	RefreshKeyword      Keyword = "refresh"
+
+	TriggerKeyword Keyword = "trigger"
+	BeforeKeyword  Keyword = "before"
+	AfterKeyword   Keyword = "after"
+	UpdateKeyword  Keyword = "update"
+	DeleteKeyword  Keyword = "delete"
+	ForKeyword     Keyword = "for"
+	EachKeyword    Keyword = "each"
+	BeginKeyword   Keyword = "begin"
 )
 
 type Symbol string
@@ -35,6 +128,15 @@ const (
 	LeftParenSymbol  Symbol = "("
 	RightParenSymbol Symbol = ")"
 	ConcatSymbol     Symbol = "||"
+	EqSymbol         Symbol = "="
+	NeqSymbol        Symbol = "<>"
+	NeqBangSymbol    Symbol = "!="
+	LtSymbol         Symbol = "<"
+	LteSymbol        Symbol = "<="
+	GtSymbol         Symbol = ">"
+	GteSymbol        Symbol = ">="
+	CastSymbol       Symbol = "::"
+	DotSymbol        Symbol = "."
 )
 
 type TokenKind uint
@@ -47,12 +149,48 @@ const (
 	NumericKind
 	BoolKind
 	IdentifierKind
+	NullKind
+	QuotedIdentifierKind
+	CommentKind
 )
 
+var tokenKindNames = []string{"keyword", "symbol", "istring", "string", "numeric", "bool", "identifier", "null", "quoted_identifier", "comment"}
+
+// MarshalText encodes the kind by name, so JSON tokens stay readable
+func (k TokenKind) MarshalText() ([]byte, error) {
+	if uint(k) >= uint(len(tokenKindNames)) {
+		return nil, fmt.Errorf("Unknown token kind %d", k)
+	}
+
+	return []byte(tokenKindNames[k]), nil
+}
+
+func (k *TokenKind) UnmarshalText(text []byte) error {
+	for i, name := range tokenKindNames {
+		if name == string(text) {
+			*k = TokenKind(i)
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("Unknown token kind %q", text)
+}
+
+// Span is the byte range [Start, End) of a token in the source
+type Span struct {
+	Start uint
+	End   uint
+}
+
 type Token struct {
 	Value string
 	Kind  TokenKind
 	Loc   Location
+	// Span and Raw give the exact source text of the token, with its quotes,
+	// escapes and case
+	Span Span
+	Raw  string
 }
 
 type cursor struct {
@@ -60,60 +198,166 @@ type cursor struct {
 	loc     Location
 }
 
+// advance moves the cursor past the character under it, counting columns
+// in characters rather than bytes. \n, \r\n and a lone \r each end a line
+func (c *cursor) advance(source string) rune {
+	r, size := utf8.DecodeRuneInString(source[c.pointer:])
+
+	c.pointer += uint(size)
+
+	isLineBreak := r == '\n' || (r == '\r' && (c.pointer >= uint(len(source)) || source[c.pointer] != '\n'))
+
+	if isLineBreak {
+		c.loc.Line++
+		c.loc.Col = 0
+	} else {
+		c.loc.Col++
+	}
+
+	return r
+}
+
 func (t *Token) Equals(other *Token) bool {
 	return t.Value == other.Value && t.Kind == other.Kind
 }
 
+// End is the location just past the token
+func (t *Token) End() Location {
+	loc := t.Loc
+	raw := []rune(t.Raw)
+
+	for i, r := range raw {
+		// \r\n is a single line break
+		if r == '\n' || (r == '\r' && (i+1 == len(raw) || raw[i+1] != '\n')) {
+			loc.Line++
+			loc.Col = 0
+
+			continue
+		}
+
+		if r != '\r' {
+			loc.Col++
+		}
+	}
+
+	return loc
+}
+
 type lexer func(string, cursor) (*Token, cursor, bool)
 
+// lexers are tried in order at every position, the first match wins
+var lexers = []lexer{
+	lexWhitespace,
+	lexComment,
+	lexKeyword,
+	lexSymbol,
+	lexString,
+	lexNumeric,
+	lexIdentifier,
+}
+
+// lexOne runs the lexers at cur and returns the first match
+func lexOne(source string, cur cursor) (*Token, cursor, bool) {
+	for _, lexer := range lexers {
+		if token, newCursor, ok := lexer(source, cur); ok {
+			return token, newCursor, true
+		}
+	}
+
+	return nil, cur, false
+}
+
+// Error reports input no lexer matches
+type Error struct {
+	Loc  Location
+	hint string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("Unable to lex token at %d:%d, token %s", e.Loc.Line, e.Loc.Col, e.hint)
+}
+
+func lexError(loc Location, last *Token) error {
+	hint := ""
+
+	if last != nil {
+		hint = " after " + last.Value
+	}
+
+	return &Error{Loc: loc, hint: hint}
+}
+
 func Lex(source string) ([]*Token, error) {
 	tokens := []*Token{}
 	cur := cursor{}
 
-lex:
 	for cur.pointer < uint(len(source)) {
-		lexers := []lexer{
-			lexKeyword,
-			lexSymbol,
-			lexString,
-			lexNumeric,
-			lexIdentifier,
-		}
-
-		for _, lexer := range lexers {
-			if token, newCursor, ok := lexer(source, cur); ok {
-				cur = newCursor
+		token, newCursor, ok := lexOne(source, cur)
 
-				if token != nil {
-					tokens = append(tokens, token)
-				}
+		if !ok {
+			var last *Token
 
-				continue lex
+			if len(tokens) > 0 {
+				last = tokens[len(tokens)-1]
 			}
+
+			return nil, lexError(cur.loc, last)
 		}
-		hint := ""
 
-		if len(tokens) > 0 {
-			hint = " after " + tokens[len(tokens)-1].Value
+		if token != nil {
+			token.Span = Span{Start: cur.pointer, End: newCursor.pointer}
+			token.Raw = source[cur.pointer:newCursor.pointer]
+			tokens = append(tokens, token)
 		}
 
-		return nil, fmt.Errorf("Unable to lex token at %d:%d, token %s", cur.loc.Line, cur.loc.Col, hint)
+		cur = newCursor
 	}
 
 	return tokens, nil
 
 }
 
+// Comments returns the comments Lex skips, as CommentKind tokens holding
+// their full text
+func Comments(source string) ([]*Token, error) {
+	comments := []*Token{}
+	cur := cursor{}
+
+	for cur.pointer < uint(len(source)) {
+		if _, newCursor, ok := lexComment(source, cur); ok {
+			comments = append(comments, &Token{
+				Value: source[cur.pointer:newCursor.pointer],
+				Kind:  CommentKind,
+				Loc:   cur.loc,
+				Span:  Span{Start: cur.pointer, End: newCursor.pointer},
+				Raw:   source[cur.pointer:newCursor.pointer],
+			})
+
+			cur = newCursor
+
+			continue
+		}
+
+		_, newCursor, ok := lexOne(source, cur)
+
+		if !ok {
+			return nil, lexError(cur.loc, nil)
+		}
+
+		cur = newCursor
+	}
+
+	return comments, nil
+}
+
 func lexNumeric(source string, ic cursor) (*Token, cursor, bool) {
 	cur := ic
 
 	periodFound := false
 	expMarkerFound := false
 
-	for ; cur.pointer < uint(len(source)); cur.pointer++ {
-
+	for cur.pointer < uint(len(source)) {
 		character := source[cur.pointer]
-		cur.loc.Col++
 
 		isDigit := character >= '0' && character <= '9'
 		isPeriod := character == '.'
@@ -127,6 +371,8 @@ func lexNumeric(source string, ic cursor) (*Token, cursor, bool) {
 			}
 
 			periodFound = isPeriod
+			cur.advance(source)
+
 			continue
 		}
 
@@ -136,8 +382,9 @@ func lexNumeric(source string, ic cursor) (*Token, cursor, bool) {
 			}
 
 			periodFound = true
-			continue
+			cur.advance(source)
 
+			continue
 		}
 
 		if isExpMarker {
@@ -154,20 +401,21 @@ func lexNumeric(source string, ic cursor) (*Token, cursor, bool) {
 				return nil, ic, false
 			}
 
-			nextCharacter := source[cur.pointer+1]
+			cur.advance(source)
 
-			if nextCharacter == '+' || nextCharacter == '-' {
-				cur.pointer++
-				cur.loc.Col++
+			if nextCharacter := source[cur.pointer]; nextCharacter == '+' || nextCharacter == '-' {
+				cur.advance(source)
 			}
 
 			continue
-
 		}
 
+		// The character that ends the number isn't part of it
 		if !isDigit {
 			break
 		}
+
+		cur.advance(source)
 	}
 
 	if cur.pointer == ic.pointer {
@@ -193,129 +441,552 @@ func lexCharacterDelimited(source string, ic cursor, delimiter byte) (*Token, cu
 		return nil, ic, false
 	}
 
-	cur.loc.Col++
-	cur.pointer++
+	cur.advance(source)
 
-	var value []byte
-
-	for ; cur.pointer < uint(len(source)); cur.pointer++ {
-		character := source[cur.pointer]
+	var value strings.Builder
 
-		if character == delimiter {
+	for cur.pointer < uint(len(source)) {
+		if source[cur.pointer] == delimiter {
 			// To escape ' in SQL you should use ''
 			// Example 'It''s a good day to be alive'
 			if cur.pointer+1 >= uint(len(source)) || source[cur.pointer+1] != delimiter {
+				cur.advance(source)
+
 				return &Token{
-					Value: string(value),
+					Value: value.String(),
 					Loc:   ic.loc,
 					Kind:  StringKind,
 				}, cur, true
-			} else {
-				value = append(value, character)
-				cur.pointer++
-				cur.loc.Col++
 			}
+
+			value.WriteString(source[cur.pointer : cur.pointer+2])
+			cur.advance(source)
+			cur.advance(source)
+
+			continue
 		}
 
-		value = append(value, character)
-		cur.loc.Col++
+		start := cur.pointer
+		cur.advance(source)
+		value.WriteString(source[start:cur.pointer])
 	}
 
 	return nil, ic, false
 }
 
 func lexString(source string, ic cursor) (*Token, cursor, bool) {
+	if token, newCursor, ok := lexEscapeString(source, ic); ok {
+		return token, newCursor, true
+	}
+
+	if token, newCursor, ok := lexDollarString(source, ic); ok {
+		return token, newCursor, true
+	}
+
 	return lexCharacterDelimited(source, ic, '\'')
 }
 
-func longestMatch(source string, ic cursor, options []string) string {
-	var value []byte
-	var skipList []int
-	var match string
-
+// lexEscapeString lexes E'...' strings, where backslash escapes such as \n,
+// \t, \xHH, \uXXXX and octal \ooo are replaced by the characters they stand for
+func lexEscapeString(source string, ic cursor) (*Token, cursor, bool) {
 	cur := ic
 
+	if !strings.HasPrefix(source[cur.pointer:], "E'") && !strings.HasPrefix(source[cur.pointer:], "e'") {
+		return nil, ic, false
+	}
+
+	cur.advance(source)
+	cur.advance(source)
+
+	var value strings.Builder
+
 	for cur.pointer < uint(len(source)) {
+		switch source[cur.pointer] {
+		case '\'':
+			if cur.pointer+1 >= uint(len(source)) || source[cur.pointer+1] != '\'' {
+				cur.advance(source)
 
-		value = append(value, strings.ToLower(string(source[cur.pointer]))...)
+				return &Token{
+					Value: value.String(),
+					Loc:   ic.loc,
+					Kind:  StringKind,
+				}, cur, true
+			}
 
-		cur.pointer++
+			value.WriteByte('\'')
+			cur.advance(source)
+			cur.advance(source)
+		case '\\':
+			cur.advance(source)
 
-	match:
-		for i, option := range options {
-			for _, skip := range skipList {
-				if i == skip {
-					continue match
-				}
+			if cur.pointer >= uint(len(source)) {
+				return nil, ic, false
 			}
-			// Deal with cases like INT vs INTO
 
-			if option == string(value) {
-				skipList = append(skipList, i)
-				if len(option) > len(match) {
-					match = option
-				}
+			r, ok := lexEscape(source, &cur)
 
-				continue
+			if !ok {
+				return nil, ic, false
 			}
 
-			sharesPrefix := string(value) == option[:cur.pointer-ic.pointer]
+			value.WriteRune(r)
+		default:
+			start := cur.pointer
+			cur.advance(source)
+			value.WriteString(source[start:cur.pointer])
+		}
+	}
+
+	return nil, ic, false
+}
+
+// lexEscape reads the escape sequence following a backslash
+func lexEscape(source string, cur *cursor) (rune, bool) {
+	simple := map[byte]rune{
+		'b': '\b',
+		'f': '\f',
+		'n': '\n',
+		'r': '\r',
+		't': '\t',
+	}
+
+	character := source[cur.pointer]
+
+	if r, ok := simple[character]; ok {
+		cur.advance(source)
+
+		return r, true
+	}
+
+	// Hexadecimal and Unicode escapes with their maximum number of digits
+	hexDigits := map[byte]int{
+		'x': 2,
+		'u': 4,
+		'U': 8,
+	}
+
+	if maxDigits, ok := hexDigits[character]; ok {
+		cur.advance(source)
+
+		return lexEscapeDigits(source, cur, 16, maxDigits, character == 'x')
+	}
+
+	if character >= '0' && character <= '7' {
+		return lexEscapeDigits(source, cur, 8, 3, true)
+	}
+
+	// Any other escaped character stands for itself
+	return cur.advance(source), true
+}
+
+// lexEscapeDigits reads up to maxDigits digits in base. Unless partial is
+// set, exactly maxDigits digits are required
+func lexEscapeDigits(source string, cur *cursor, base int, maxDigits int, partial bool) (rune, bool) {
+	var r rune
+	digits := 0
+
+	for ; digits < maxDigits && cur.pointer < uint(len(source)); digits++ {
+		character := source[cur.pointer]
+		digit := base
+
+		switch {
+		case character >= '0' && character <= '9':
+			digit = int(character - '0')
+		case character >= 'a' && character <= 'f':
+			digit = int(character-'a') + 10
+		case character >= 'A' && character <= 'F':
+			digit = int(character-'A') + 10
+		}
+
+		if digit >= base {
+			break
+		}
 
-			tooLong := len(value) > len(option)
+		r = r*rune(base) + rune(digit)
+		cur.advance(source)
+	}
+
+	if digits == 0 || (!partial && digits < maxDigits) || !utf8.ValidRune(r) {
+		return 0, false
+	}
 
-			if tooLong || !sharesPrefix {
-				skipList = append(skipList, i)
+	return r, true
+}
+
+// lexDollarString lexes $$...$$ and $tag$...$tag$ strings, whose contents
+// are taken literally
+func lexDollarString(source string, ic cursor) (*Token, cursor, bool) {
+	cur := ic
+
+	if cur.pointer >= uint(len(source)) || source[cur.pointer] != '$' {
+		return nil, ic, false
+	}
+
+	cur.advance(source)
+
+	for cur.pointer < uint(len(source)) && source[cur.pointer] != '$' {
+		r, _ := utf8.DecodeRuneInString(source[cur.pointer:])
+
+		isTagStart := cur.pointer == ic.pointer+1 && (unicode.IsLetter(r) || r == '_')
+		isTagPart := cur.pointer > ic.pointer+1 && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+
+		if !isTagStart && !isTagPart {
+			return nil, ic, false
+		}
+
+		cur.advance(source)
+	}
+
+	if cur.pointer >= uint(len(source)) {
+		return nil, ic, false
+	}
+
+	cur.advance(source)
+
+	delimiter := source[ic.pointer:cur.pointer]
+
+	end := strings.Index(source[cur.pointer:], delimiter)
+
+	if end < 0 {
+		return nil, ic, false
+	}
+
+	value := source[cur.pointer : cur.pointer+uint(end)]
+
+	for cur.pointer < ic.pointer+uint(len(delimiter)*2+end) {
+		cur.advance(source)
+	}
+
+	return &Token{
+		Value: value,
+		Loc:   ic.loc,
+		Kind:  StringKind,
+	}, cur, true
+}
+
+// trie holds the keywords or symbols so the longest one at a position is
+// found in a single pass over the source
+type trie struct {
+	children map[byte]*trie
+	terminal bool
+}
+
+func newTrie(options []string) *trie {
+	root := &trie{children: map[byte]*trie{}}
+
+	for _, option := range options {
+		node := root
+
+		for i := 0; i < len(option); i++ {
+			child, ok := node.children[option[i]]
+
+			if !ok {
+				child = &trie{children: map[byte]*trie{}}
+				node.children[option[i]] = child
 			}
+
+			node = child
+		}
+
+		node.terminal = true
+	}
+
+	return root
+}
+
+// longestMatch returns the longest option the source starts with at ic,
+// ignoring ASCII case. Deals with cases like INT vs INTO
+func (t *trie) longestMatch(source string, ic cursor) string {
+	node := t
+	end := ic.pointer
+
+	for pointer := ic.pointer; pointer < uint(len(source)); pointer++ {
+		c := source[pointer]
+
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
 		}
 
-		if len(skipList) == len(options) {
+		child, ok := node.children[c]
+
+		if !ok {
 			break
 		}
+
+		node = child
+
+		if node.terminal {
+			end = pointer + 1
+		}
 	}
 
-	return match
+	return strings.ToLower(source[ic.pointer:end])
 }
 
-func lexSymbol(source string, ic cursor) (*Token, cursor, bool) {
-	character := source[ic.pointer]
+// lexWhitespace skips line breaks and any Unicode whitespace
+func lexWhitespace(source string, ic cursor) (*Token, cursor, bool) {
+	cur := ic
+
+	for cur.pointer < uint(len(source)) {
+		r, _ := utf8.DecodeRuneInString(source[cur.pointer:])
+
+		if !unicode.IsSpace(r) {
+			break
+		}
+
+		cur.advance(source)
+	}
 
+	if cur.pointer == ic.pointer {
+		return nil, ic, false
+	}
+
+	return nil, cur, true
+}
+
+// lexComment skips -- line comments and /* */ block comments, which may be
+// nested
+func lexComment(source string, ic cursor) (*Token, cursor, bool) {
 	cur := ic
 
-	cur.pointer++
-	cur.loc.Col++
-
-	switch character {
-	// Remove whitespaces
-	case '\n':
-		cur.loc.Line++
-		cur.loc.Col = 0
-		fallthrough
-	case '\t':
-		fallthrough
-	case ' ':
+	if strings.HasPrefix(source[cur.pointer:], "--") {
+		for cur.pointer < uint(len(source)) && source[cur.pointer] != '\n' && source[cur.pointer] != '\r' {
+			cur.advance(source)
+		}
+
 		return nil, cur, true
 	}
 
-	symbols := []Symbol{
-		CommaSymbol,
-		LeftParenSymbol,
-		RightParenSymbol,
-		SemicolonSymbol,
-		AsteriskSymbol,
+	if !strings.HasPrefix(source[cur.pointer:], "/*") {
+		return nil, ic, false
+	}
+
+	depth := 0
+
+	for cur.pointer < uint(len(source)) {
+		rest := source[cur.pointer:]
+
+		switch {
+		case strings.HasPrefix(rest, "/*"):
+			depth++
+		case strings.HasPrefix(rest, "*/"):
+			depth--
+		default:
+			cur.advance(source)
+			continue
+		}
+
+		cur.pointer += 2
+		cur.loc.Col += 2
+
+		if depth == 0 {
+			return nil, cur, true
+		}
 	}
 
+	// Unterminated block comment
+	return nil, ic, false
+}
+
+var symbols = []Symbol{
+	CommaSymbol,
+	LeftParenSymbol,
+	RightParenSymbol,
+	SemicolonSymbol,
+	AsteriskSymbol,
+	EqSymbol,
+	NeqSymbol,
+	NeqBangSymbol,
+	LtSymbol,
+	LteSymbol,
+	GtSymbol,
+	GteSymbol,
+	ConcatSymbol,
+	CastSymbol,
+	DotSymbol,
+}
+
+var keywords = []Keyword{
+	SelectKeyword,
+	InsertKeyword,
+	ValuesKeyword,
+	TableKeyword,
+	CreateKeyword,
+	WhereKeyword,
+	FromKeyword,
+	IntoKeyword,
+	TextKeyword,
+	IntKeyword,
+	BooleanKeyword,
+	TrueKeyword,
+	FalseKeyword,
+	BigintKeyword,
+	RealKeyword,
+	DoubleKeyword,
+	NumericKeyword,
+	VarcharKeyword,
+	DateKeyword,
+	TimestampKeyword,
+	BlobKeyword,
+	NullKeyword,
+	IsKeyword,
+	NotKeyword,
+	AndKeyword,
+	OrKeyword,
+	DistinctKeyword,
+	CoalesceKeyword,
+	NullifKeyword,
+	AlterKeyword,
+	AddKeyword,
+	DropKeyword,
+	RenameKeyword,
+	ColumnKeyword,
+	ToKeyword,
+	DefaultKeyword,
+	AsKeyord,
+	InKeyword,
+	ExistsKeyword,
+	WithKeyword,
+	RecursiveKeyword,
+	UnionKeyword,
+	IntersectKeyword,
+	ExceptKeyword,
+	AllKeyword,
+	OrderKeyword,
+	ByKeyword,
+	AscKeyword,
+	DescKeyword,
+	LimitKeyword,
+	OffsetKeyword,
+	OverKeyword,
+	PartitionKeyword,
+	RowsKeyword,
+	RangeKeyword,
+	BetweenKeyword,
+	UnboundedKeyword,
+	PrecedingKeyword,
+	FollowingKeyword,
+	CurrentKeyword,
+	RowKeyword,
+	RowNumberKeyword,
+	OnKeyword,
+	ExtractKeyword,
+	DateTruncKeyword,
+	CaseKeyword,
+	WhenKeyword,
+	ThenKeyword,
+	ElseKeyword,
+	EndKeyword,
+	CastKeyword,
+	LikeKeyword,
+	IlikeKeyword,
+	EscapeKeyword,
+	SchemaKeyword,
+	AttachKeyword,
+	DatabaseKeyword,
+	ViewKeyword,
+	MaterializedKeyword,
+	RefreshKeyword,
+	TriggerKeyword,
+	BeforeKeyword,
+	AfterKeyword,
+	UpdateKeyword,
+	DeleteKeyword,
+	ForKeyword,
+	EachKeyword,
+	BeginKeyword,
+}
+
+// reservedKeywords can't be used as identifiers. Every other keyword is
+// non-reserved and may also name a table, column or alias
+var reservedKeywords = map[Keyword]bool{
+	SelectKeyword:    true,
+	FromKeyword:      true,
+	AsKeyord:         true,
+	TableKeyword:     true,
+	CreateKeyword:    true,
+	IntoKeyword:      true,
+	WhereKeyword:     true,
+	TrueKeyword:      true,
+	FalseKeyword:     true,
+	NullKeyword:      true,
+	IsKeyword:        true,
+	NotKeyword:       true,
+	AndKeyword:       true,
+	OrKeyword:        true,
+	DistinctKeyword:  true,
+	ColumnKeyword:    true,
+	ToKeyword:        true,
+	DefaultKeyword:   true,
+	InKeyword:        true,
+	ExistsKeyword:    true,
+	WithKeyword:      true,
+	UnionKeyword:     true,
+	IntersectKeyword: true,
+	ExceptKeyword:    true,
+	AllKeyword:       true,
+	OrderKeyword:     true,
+	AscKeyword:       true,
+	DescKeyword:      true,
+	LimitKeyword:     true,
+	OffsetKeyword:    true,
+	BetweenKeyword:   true,
+	OnKeyword:        true,
+	CaseKeyword:      true,
+	WhenKeyword:      true,
+	ThenKeyword:      true,
+	ElseKeyword:      true,
+	EndKeyword:       true,
+	CastKeyword:      true,
+	LikeKeyword:      true,
+	IlikeKeyword:     true,
+	BeginKeyword:     true,
+}
+
+// Reserved reports whether the keyword can't be used as an identifier
+func (k Keyword) Reserved() bool {
+	return reservedKeywords[k]
+}
+
+// Keywords returns every keyword the lexer recognizes
+func Keywords() []Keyword {
+	return append([]Keyword(nil), keywords...)
+}
+
+var symbolTrie = newTrie(func() []string {
 	var options []string
 
 	for _, sym := range symbols {
 		options = append(options, string(sym))
 	}
 
-	match := longestMatch(source, ic, options)
+	return options
+}())
+
+var keywordTrie = newTrie(func() []string {
+	var options []string
+
+	for _, kw := range keywords {
+		options = append(options, string(kw))
+	}
+
+	return options
+}())
+
+func lexSymbol(source string, ic cursor) (*Token, cursor, bool) {
+	cur := ic
+
+	match := symbolTrie.longestMatch(source, ic)
 
 	if match == "" {
 		return nil, ic, false
 	}
 
+	// .5 is a number, not a qualifier
+	if next := ic.pointer + 1; match == string(DotSymbol) && next < uint(len(source)) && source[next] >= '0' && source[next] <= '9' {
+		return nil, ic, false
+	}
+
 	cur.pointer = ic.pointer + uint(len(match))
 	cur.loc.Col = ic.loc.Col + uint(len(match))
 
@@ -329,84 +1000,80 @@ func lexSymbol(source string, ic cursor) (*Token, cursor, bool) {
 func lexKeyword(source string, ic cursor) (*Token, cursor, bool) {
 	cur := ic
 
-	keywords := []Keyword{
-		SelectKeyword,
-		InsertKeyword,
-		ValuesKeyword,
-		TableKeyword,
-		CreateKeyword,
-		WhereKeyword,
-		FromKeyword,
-		IntoKeyword,
-		TextKeyword,
-		IntKeyword,
+	match := keywordTrie.longestMatch(source, ic)
+
+	if match == "" {
+		return nil, ic, false
 	}
 
-	var options []string
+	cur.pointer = ic.pointer + uint(len(match))
+	cur.loc.Col = ic.loc.Col + uint(len(match))
 
-	for _, kw := range keywords {
-		options = append(options, string(kw))
+	// A keyword must end at a word boundary, selected is an identifier
+	if r, _ := utf8.DecodeRuneInString(source[cur.pointer:]); isIdentifierRune(r) {
+		return nil, ic, false
 	}
 
-	match := longestMatch(source, ic, options)
+	kind := KeywordKind
 
-	if match == "" {
-		return nil, ic, false
+	// TRUE, FALSE and NULL are literals, not keywords
+	if match == string(TrueKeyword) || match == string(FalseKeyword) {
+		kind = BoolKind
 	}
 
-	cur.pointer = ic.pointer + uint(len(match))
-	cur.loc.Col = ic.loc.Col + uint(len(match))
+	if match == string(NullKeyword) {
+		kind = NullKind
+	}
 
 	return &Token{
 		Value: match,
-		Kind:  KeywordKind,
+		Kind:  kind,
 		Loc:   ic.loc,
 	}, cur, true
 }
 
+func isIdentifierRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$'
+}
+
 func lexIdentifier(source string, ic cursor) (*Token, cursor, bool) {
-	//Handle separetely if is a double-quoted identifier
+	//Handle separetely if is a double-quoted identifier, which keeps its case
 	if token, newCursor, ok := lexCharacterDelimited(source, ic, '"'); ok {
+		token.Kind = QuotedIdentifierKind
+		// "" stands for a " in the name. Raw keeps it escaped
+		token.Value = strings.ReplaceAll(token.Value, `""`, `"`)
+
 		return token, newCursor, true
 	}
 
 	cur := ic
 
-	character := source[cur.pointer]
-
-	isAlphabetical := (character >= 'A' && character <= 'Z') || (character >= 'a' && character <= 'z')
+	r, _ := utf8.DecodeRuneInString(source[cur.pointer:])
 
-	if !isAlphabetical {
+	if !unicode.IsLetter(r) {
 		return nil, ic, false
 	}
 
-	cur.pointer++
-	cur.loc.Col++
-
-	value := []byte{character}
-
-	for ; cur.pointer < uint(len(source)); cur.pointer++ {
-		character = source[cur.pointer]
+	// E' starts an escape string, not the identifier e
+	if (r == 'E' || r == 'e') && strings.HasPrefix(source[cur.pointer+1:], "'") {
+		return nil, ic, false
+	}
 
-		isAlphabetical = (character >= 'A' && character <= 'Z') || (character >= 'a' && character <= 'z')
-		isNumeric := character >= '0' && character <= '9'
+	cur.advance(source)
 
-		if isAlphabetical || isNumeric || character == '_' || character == '$' {
-			value = append(value, character)
+	for cur.pointer < uint(len(source)) {
+		r, _ = utf8.DecodeRuneInString(source[cur.pointer:])
 
-			cur.loc.Col++
+		if isIdentifierRune(r) {
+			cur.advance(source)
 			continue
 		}
 
 		break
 	}
 
-	if len(value) == 0 {
-		return nil, ic, false
-	}
-
 	return &Token{
-		Value: strings.ToLower(string(value)),
+		Value: strings.ToLower(source[ic.pointer:cur.pointer]),
 		Loc:   ic.loc,
 		Kind:  IdentifierKind,
 	}, cur, true
@@ -138,6 +138,85 @@ func TestToken_lexString(t *testing.T) {
 	}
 }
 
+func TestToken_lexEscapedString(t *testing.T) {
+	tests := []struct {
+		string bool
+		input  string
+		value  string
+	}{
+		{
+			string: true,
+			input:  `E'a\tb\nc'`,
+			value:  "a\tb\nc",
+		},
+		{
+			string: true,
+			input:  `e'It\'s ''here'' \\ \q'`,
+			value:  `It's 'here' \ q`,
+		},
+		{
+			string: true,
+			input:  `E'\x41\x4a\101\7'`,
+			value:  "AJA\a",
+		},
+		{
+			string: true,
+			input:  `E'caf\u00e9 \U0001F600'`,
+			value:  "café 😀",
+		},
+		{
+			string: true,
+			input:  `$$It's $ a 'dollar' string$$`,
+			value:  `It's $ a 'dollar' string`,
+		},
+		{
+			string: true,
+			input:  `$fn$ $$ inner $$ $fn$`,
+			value:  ` $$ inner $$ `,
+		},
+		{
+			string: true,
+			input:  `$$$$`,
+			value:  ``,
+		},
+		{
+			string: true,
+			input:  `'ünïcödé'`,
+			value:  `ünïcödé`,
+		},
+		// false tests
+		{
+			string: false,
+			input:  `E'\u00g0'`,
+		},
+		{
+			string: false,
+			input:  `E'unterminated\'`,
+		},
+		{
+			string: false,
+			input:  `$$unterminated$`,
+		},
+		{
+			string: false,
+			input:  `$1$ a $1$`,
+		},
+		{
+			string: false,
+			input:  `$a b$ a $a b$`,
+		},
+	}
+
+	for _, test := range tests {
+		tok, _, ok := lexString(test.input, cursor{})
+		assert.Equal(t, test.string, ok, test.input)
+		if ok {
+			assert.Equal(t, test.value, tok.Value, test.input)
+			assert.Equal(t, StringKind, tok.Kind, test.input)
+		}
+	}
+}
+
 func TestToken_lexSymbol(t *testing.T) {
 	tests := []struct {
 		symbol bool
@@ -151,6 +230,39 @@ func TestToken_lexSymbol(t *testing.T) {
 			symbol: true,
 			value:  "*",
 		},
+		{
+			symbol: true,
+			value:  "<>",
+		},
+		{
+			symbol: true,
+			value:  ">=",
+		},
+		{
+			symbol: true,
+			value:  "!=",
+		},
+		{
+			symbol: true,
+			value:  "||",
+		},
+		{
+			symbol: true,
+			value:  "::",
+		},
+		{
+			symbol: true,
+			value:  ".",
+		},
+		// false tests
+		{
+			symbol: false,
+			value:  "!",
+		},
+		{
+			symbol: false,
+			value:  ".5",
+		},
 	}
 
 	for _, test := range tests {
@@ -163,6 +275,120 @@ func TestToken_lexSymbol(t *testing.T) {
 	}
 }
 
+func TestToken_lexWhitespace(t *testing.T) {
+	tests := []struct {
+		whitespace bool
+		value      string
+		loc        Location
+	}{
+		{
+			whitespace: true,
+			value:      " \t",
+			loc:        Location{Line: 0, Col: 2},
+		},
+		{
+			whitespace: true,
+			value:      "\n \r\n  \r ",
+			loc:        Location{Line: 3, Col: 1},
+		},
+		{
+			whitespace: true,
+			value:      "\u00a0\u2003\v\f",
+			loc:        Location{Line: 0, Col: 4},
+		},
+		{
+			whitespace: true,
+			value:      "  a",
+			loc:        Location{Line: 0, Col: 2},
+		},
+		// false tests
+		{
+			whitespace: false,
+			value:      "a ",
+		},
+		{
+			whitespace: false,
+			value:      "\u200b",
+		},
+	}
+
+	for _, test := range tests {
+		tok, cur, ok := lexWhitespace(test.value, cursor{})
+		assert.Equal(t, test.whitespace, ok, test.value)
+		assert.Nil(t, tok, test.value)
+		if ok {
+			assert.Equal(t, test.loc, cur.loc, test.value)
+		}
+	}
+}
+
+func TestToken_lexComment(t *testing.T) {
+	tests := []struct {
+		comment bool
+		value   string
+		rest    string
+		loc     Location
+	}{
+		{
+			comment: true,
+			value:   "-- a comment",
+			rest:    "",
+			loc:     Location{Line: 0, Col: 12},
+		},
+		{
+			comment: true,
+			value:   "-- a comment\r\nselect",
+			rest:    "\r\nselect",
+			loc:     Location{Line: 0, Col: 12},
+		},
+		{
+			comment: true,
+			value:   "/* a */b",
+			rest:    "b",
+			loc:     Location{Line: 0, Col: 7},
+		},
+		{
+			comment: true,
+			value:   "/* a /* nested\r\n */ comment\n*/ b",
+			rest:    " b",
+			loc:     Location{Line: 2, Col: 2},
+		},
+		{
+			comment: true,
+			value:   "/**/",
+			rest:    "",
+			loc:     Location{Line: 0, Col: 4},
+		},
+		// false tests
+		{
+			comment: false,
+			value:   "- a",
+		},
+		{
+			comment: false,
+			value:   "/ a",
+		},
+		{
+			comment: false,
+			value:   "/* unterminated",
+		},
+		{
+			comment: false,
+			value:   "/* a /* nested */",
+		},
+	}
+
+	for _, test := range tests {
+		tok, cur, ok := lexComment(test.value, cursor{})
+		assert.Equal(t, test.comment, ok, test.value)
+		assert.Nil(t, tok, test.value)
+		if ok {
+			assert.Equal(t, test.rest, test.value[cur.pointer:], test.value)
+			assert.Equal(t, test.loc, cur.loc, test.value)
+		}
+	}
+}
+
 func TestToken_lexIdentifier(t *testing.T) {
 	tests := []struct {
 		Identifier bool
@@ -189,6 +415,21 @@ func TestToken_lexIdentifier(t *testing.T) {
 			input:      `" abc "`,
 			value:      ` abc `,
 		},
+		{
+			Identifier: true,
+			input:      `"My""T"`,
+			value:      `My"T`,
+		},
+		{
+			Identifier: true,
+			input:      `"MyTable"`,
+			value:      `MyTable`,
+		},
+		{
+			Identifier: true,
+			input:      "MyTable",
+			value:      "mytable",
+		},
 		{
 			Identifier: true,
 			input:      "a9$",
@@ -204,6 +445,21 @@ func TestToken_lexIdentifier(t *testing.T) {
 			input:      `"userName"`,
 			value:      "userName",
 		},
+		{
+			Identifier: true,
+			input:      "Größe",
+			value:      "größe",
+		},
+		{
+			Identifier: true,
+			input:      "名前_1 ",
+			value:      "名前_1",
+		},
+		{
+			Identifier: true,
+			input:      "ÉLAN$",
+			value:      "élan$",
+		},
 		// false tests
 		{
 			Identifier: false,
@@ -221,6 +477,10 @@ func TestToken_lexIdentifier(t *testing.T) {
 			Identifier: false,
 			input:      " abc",
 		},
+		{
+			Identifier: false,
+			input:      "١abc",
+		},
 	}
 
 	for _, test := range tests {
@@ -228,6 +488,14 @@ func TestToken_lexIdentifier(t *testing.T) {
 		assert.Equal(t, test.Identifier, ok, test.input)
 		if ok {
 			assert.Equal(t, test.value, tok.Value, test.input)
+
+			kind := IdentifierKind
+
+			if strings.HasPrefix(test.input, `"`) {
+				kind = QuotedIdentifierKind
+			}
+
+			assert.Equal(t, kind, tok.Kind, test.input)
 		}
 	}
 }
@@ -253,6 +521,22 @@ func TestToken_lexKeyword(t *testing.T) {
 			keyword: true,
 			value:   "into",
 		},
+		{
+			keyword: true,
+			value:   "VARCHAR",
+		},
+		{
+			keyword: true,
+			value:   "bigint",
+		},
+		{
+			keyword: true,
+			value:   "timestamp",
+		},
+		{
+			keyword: true,
+			value:   "false",
+		},
 		// false tests
 		{
 			keyword: false,
@@ -262,6 +546,14 @@ func TestToken_lexKeyword(t *testing.T) {
 			keyword: false,
 			value:   "flubbrety",
 		},
+		{
+			keyword: false,
+			value:   "selected",
+		},
+		{
+			keyword: false,
+			value:   "interval",
+		},
 	}
 
 	for _, test := range tests {
@@ -274,6 +566,33 @@ func TestToken_lexKeyword(t *testing.T) {
 	}
 }
 
+func TestLex_keywordBoundaries(t *testing.T) {
+	for _, kw := range Keywords() {
+		kind := KeywordKind
+
+		switch kw {
+		case TrueKeyword, FalseKeyword:
+			kind = BoolKind
+		case NullKeyword:
+			kind = NullKind
+		}
+
+		for _, input := range []string{string(kw), strings.ToUpper(string(kw))} {
+			tokens, err := Lex(input + "(")
+			assert.Nil(t, err, input)
+			assert.Len(t, tokens, 2, input)
+			assert.Equal(t, Token{Value: string(kw), Kind: kind, Span: Span{End: uint(len(kw))}, Raw: input}, *tokens[0], input)
+		}
+
+		// A keyword running into more word characters is an identifier
+		for _, input := range []string{string(kw) + "d", string(kw) + "_1", string(kw) + "9", string(kw) + "ä", "x" + string(kw)} {
+			tokens, err := Lex(input)
+			assert.Nil(t, err, input)
+			assert.Equal(t, []*Token{{Value: input, Kind: IdentifierKind, Span: Span{End: uint(len(input))}, Raw: input}}, tokens, input)
+		}
+	}
+}
+
 func TestLex(t *testing.T) {
 	tests := []struct {
 		input  string
@@ -287,11 +606,15 @@ func TestLex(t *testing.T) {
 					Loc:   Location{Col: 0, Line: 0},
 					Value: string(SelectKeyword),
 					Kind:  KeywordKind,
+					Span:  Span{Start: 0, End: 6},
+					Raw:   "select",
 				},
 				{
 					Loc:   Location{Col: 7, Line: 0},
 					Value: "a",
 					Kind:  IdentifierKind,
+					Span:  Span{Start: 7, End: 8},
+					Raw:   "a",
 				},
 			},
 		},
@@ -302,11 +625,53 @@ func TestLex(t *testing.T) {
 					Loc:   Location{Col: 0, Line: 0},
 					Value: string(SelectKeyword),
 					Kind:  KeywordKind,
+					Span:  Span{Start: 0, End: 6},
+					Raw:   "select",
 				},
 				{
 					Loc:   Location{Col: 7, Line: 0},
 					Value: "true",
-					Kind:  IdentifierKind,
+					Kind:  BoolKind,
+					Span:  Span{Start: 7, End: 11},
+					Raw:   "true",
+				},
+			},
+		},
+		{
+			input: "select FALSE",
+			Tokens: []Token{
+				{
+					Loc:   Location{Col: 0, Line: 0},
+					Value: string(SelectKeyword),
+					Kind:  KeywordKind,
+					Span:  Span{Start: 0, End: 6},
+					Raw:   "select",
+				},
+				{
+					Loc:   Location{Col: 7, Line: 0},
+					Value: "false",
+					Kind:  BoolKind,
+					Span:  Span{Start: 7, End: 12},
+					Raw:   "FALSE",
+				},
+			},
+		},
+		{
+			input: "select NULL",
+			Tokens: []Token{
+				{
+					Loc:   Location{Col: 0, Line: 0},
+					Value: string(SelectKeyword),
+					Kind:  KeywordKind,
+					Span:  Span{Start: 0, End: 6},
+					Raw:   "select",
+				},
+				{
+					Loc:   Location{Col: 7, Line: 0},
+					Value: "null",
+					Kind:  NullKind,
+					Span:  Span{Start: 7, End: 11},
+					Raw:   "NULL",
 				},
 			},
 		},
@@ -317,11 +682,15 @@ func TestLex(t *testing.T) {
 					Loc:   Location{Col: 0, Line: 0},
 					Value: string(SelectKeyword),
 					Kind:  KeywordKind,
+					Span:  Span{Start: 0, End: 6},
+					Raw:   "select",
 				},
 				{
 					Loc:   Location{Col: 7, Line: 0},
 					Value: "1",
 					Kind:  NumericKind,
+					Span:  Span{Start: 7, End: 8},
+					Raw:   "1",
 				},
 			},
 			err: nil,
@@ -333,51 +702,71 @@ func TestLex(t *testing.T) {
 					Loc:   Location{Col: 0, Line: 0},
 					Value: string(CreateKeyword),
 					Kind:  KeywordKind,
+					Span:  Span{Start: 0, End: 6},
+					Raw:   "CREATE",
 				},
 				{
 					Loc:   Location{Col: 7, Line: 0},
 					Value: string(TableKeyword),
 					Kind:  KeywordKind,
+					Span:  Span{Start: 7, End: 12},
+					Raw:   "TABLE",
 				},
 				{
 					Loc:   Location{Col: 13, Line: 0},
 					Value: "u",
 					Kind:  IdentifierKind,
+					Span:  Span{Start: 13, End: 14},
+					Raw:   "u",
 				},
 				{
 					Loc:   Location{Col: 15, Line: 0},
 					Value: "(",
 					Kind:  SymbolKind,
+					Span:  Span{Start: 15, End: 16},
+					Raw:   "(",
 				},
 				{
 					Loc:   Location{Col: 16, Line: 0},
 					Value: "id",
 					Kind:  IdentifierKind,
+					Span:  Span{Start: 16, End: 18},
+					Raw:   "id",
 				},
 				{
 					Loc:   Location{Col: 19, Line: 0},
 					Value: "int",
 					Kind:  KeywordKind,
+					Span:  Span{Start: 19, End: 22},
+					Raw:   "INT",
 				},
 				{
 					Loc:   Location{Col: 22, Line: 0},
 					Value: ",",
 					Kind:  SymbolKind,
+					Span:  Span{Start: 22, End: 23},
+					Raw:   ",",
 				},
 				{
 					Loc:   Location{Col: 24, Line: 0},
 					Value: "name",
 					Kind:  IdentifierKind,
+					Span:  Span{Start: 24, End: 28},
+					Raw:   "name",
 				},
 				{
 					Loc:   Location{Col: 29, Line: 0},
 					Value: "text",
 					Kind:  KeywordKind,
+					Span:  Span{Start: 29, End: 33},
+					Raw:   "TEXT",
 				},
 				{
 					Loc:   Location{Col: 33, Line: 0},
 					Value: ")",
 					Kind:  SymbolKind,
+					Span:  Span{Start: 33, End: 34},
+					Raw:   ")",
 				},
 			},
 		},
@@ -388,50 +777,277 @@ func TestLex(t *testing.T) {
 					Loc:   Location{Col: 0, Line: 0},
 					Value: string(InsertKeyword),
 					Kind:  KeywordKind,
+					Span:  Span{Start: 0, End: 6},
+					Raw:   "insert",
 				},
 				{
 					Loc:   Location{Col: 7, Line: 0},
 					Value: string(IntoKeyword),
 					Kind:  KeywordKind,
+					Span:  Span{Start: 7, End: 11},
+					Raw:   "into",
 				},
 				{
 					Loc:   Location{Col: 12, Line: 0},
 					Value: "users",
 					Kind:  IdentifierKind,
+					Span:  Span{Start: 12, End: 17},
+					Raw:   "users",
 				},
 				{
 					Loc:   Location{Col: 18, Line: 0},
 					Value: string(ValuesKeyword),
 					Kind:  KeywordKind,
+					Span:  Span{Start: 18, End: 24},
+					Raw:   "Values",
 				},
 				{
 					Loc:   Location{Col: 25, Line: 0},
 					Value: "(",
 					Kind:  SymbolKind,
+					Span:  Span{Start: 25, End: 26},
+					Raw:   "(",
 				},
 				{
 					Loc:   Location{Col: 26, Line: 0},
 					Value: "105",
 					Kind:  NumericKind,
+					Span:  Span{Start: 26, End: 29},
+					Raw:   "105",
 				},
 				{
-					Loc:   Location{Col: 30, Line: 0},
+					Loc:   Location{Col: 29, Line: 0},
 					Value: ",",
 					Kind:  SymbolKind,
+					Span:  Span{Start: 29, End: 30},
+					Raw:   ",",
 				},
 				{
-					Loc:   Location{Col: 32, Line: 0},
+					Loc:   Location{Col: 31, Line: 0},
 					Value: "233",
 					Kind:  NumericKind,
+					Span:  Span{Start: 31, End: 34},
+					Raw:   "233",
 				},
 				{
-					Loc:   Location{Col: 36, Line: 0},
+					Loc:   Location{Col: 34, Line: 0},
 					Value: ")",
 					Kind:  SymbolKind,
+					Span:  Span{Start: 34, End: 35},
+					Raw:   ")",
 				},
 			},
 			err: nil,
 		},
+		{
+			input: "select 'a', \"b\"",
+			Tokens: []Token{
+				{
+					Loc:   Location{Col: 0, Line: 0},
+					Value: string(SelectKeyword),
+					Kind:  KeywordKind,
+					Span:  Span{Start: 0, End: 6},
+					Raw:   "select",
+				},
+				{
+					Loc:   Location{Col: 7, Line: 0},
+					Value: "a",
+					Kind:  StringKind,
+					Span:  Span{Start: 7, End: 10},
+					Raw:   "'a'",
+				},
+				{
+					Loc:   Location{Col: 10, Line: 0},
+					Value: ",",
+					Kind:  SymbolKind,
+					Span:  Span{Start: 10, End: 11},
+					Raw:   ",",
+				},
+				{
+					Loc:   Location{Col: 12, Line: 0},
+					Value: "b",
+					Kind:  QuotedIdentifierKind,
+					Span:  Span{Start: 12, End: 15},
+					Raw:   "\"b\"",
+				},
+			},
+			err: nil,
+		},
+		{
+			input: "select a -- comment\r\nfrom t /* x /* nested */ y */;",
+			Tokens: []Token{
+				{
+					Loc:   Location{Col: 0, Line: 0},
+					Value: string(SelectKeyword),
+					Kind:  KeywordKind,
+					Span:  Span{Start: 0, End: 6},
+					Raw:   "select",
+				},
+				{
+					Loc:   Location{Col: 7, Line: 0},
+					Value: "a",
+					Kind:  IdentifierKind,
+					Span:  Span{Start: 7, End: 8},
+					Raw:   "a",
+				},
+				{
+					Loc:   Location{Col: 0, Line: 1},
+					Value: string(FromKeyword),
+					Kind:  KeywordKind,
+					Span:  Span{Start: 21, End: 25},
+					Raw:   "from",
+				},
+				{
+					Loc:   Location{Col: 5, Line: 1},
+					Value: "t",
+					Kind:  IdentifierKind,
+					Span:  Span{Start: 26, End: 27},
+					Raw:   "t",
+				},
+				{
+					Loc:   Location{Col: 29, Line: 1},
+					Value: ";",
+					Kind:  SymbolKind,
+					Span:  Span{Start: 50, End: 51},
+					Raw:   ";",
+				},
+			},
+			err: nil,
+		},
+		{
+			input: "select\r\n\ta\r,\u00a0b",
+			Tokens: []Token{
+				{
+					Loc:   Location{Col: 0, Line: 0},
+					Value: string(SelectKeyword),
+					Kind:  KeywordKind,
+					Span:  Span{Start: 0, End: 6},
+					Raw:   "select",
+				},
+				{
+					Loc:   Location{Col: 1, Line: 1},
+					Value: "a",
+					Kind:  IdentifierKind,
+					Span:  Span{Start: 9, End: 10},
+					Raw:   "a",
+				},
+				{
+					Loc:   Location{Col: 0, Line: 2},
+					Value: ",",
+					Kind:  SymbolKind,
+					Span:  Span{Start: 11, End: 12},
+					Raw:   ",",
+				},
+				{
+					Loc:   Location{Col: 2, Line: 2},
+					Value: "b",
+					Kind:  IdentifierKind,
+					Span:  Span{Start: 14, End: 15},
+					Raw:   "b",
+				},
+			},
+			err: nil,
+		},
+		{
+			input: "select 'héllo', Größe\n  from \"tàble\" /* ü */ ;",
+			Tokens: []Token{
+				{
+					Loc:   Location{Col: 0, Line: 0},
+					Value: string(SelectKeyword),
+					Kind:  KeywordKind,
+					Span:  Span{Start: 0, End: 6},
+					Raw:   "select",
+				},
+				{
+					Loc:   Location{Col: 7, Line: 0},
+					Value: "héllo",
+					Kind:  StringKind,
+					Span:  Span{Start: 7, End: 15},
+					Raw:   "'héllo'",
+				},
+				{
+					Loc:   Location{Col: 14, Line: 0},
+					Value: ",",
+					Kind:  SymbolKind,
+					Span:  Span{Start: 15, End: 16},
+					Raw:   ",",
+				},
+				{
+					Loc:   Location{Col: 16, Line: 0},
+					Value: "größe",
+					Kind:  IdentifierKind,
+					Span:  Span{Start: 17, End: 24},
+					Raw:   "Größe",
+				},
+				{
+					Loc:   Location{Col: 2, Line: 1},
+					Value: string(FromKeyword),
+					Kind:  KeywordKind,
+					Span:  Span{Start: 27, End: 31},
+					Raw:   "from",
+				},
+				{
+					Loc:   Location{Col: 7, Line: 1},
+					Value: "tàble",
+					Kind:  QuotedIdentifierKind,
+					Span:  Span{Start: 32, End: 40},
+					Raw:   "\"tàble\"",
+				},
+				{
+					Loc:   Location{Col: 23, Line: 1},
+					Value: ";",
+					Kind:  SymbolKind,
+					Span:  Span{Start: 50, End: 51},
+					Raw:   ";",
+				},
+			},
+			err: nil,
+		},
+		{
+			input: "select 'multi\nline', E'\\n' $$x$$",
+			Tokens: []Token{
+				{
+					Loc:   Location{Col: 0, Line: 0},
+					Value: string(SelectKeyword),
+					Kind:  KeywordKind,
+					Span:  Span{Start: 0, End: 6},
+					Raw:   "select",
+				},
+				{
+					Loc:   Location{Col: 7, Line: 0},
+					Value: "multi\nline",
+					Kind:  StringKind,
+					Span:  Span{Start: 7, End: 19},
+					Raw:   "'multi\nline'",
+				},
+				{
+					Loc:   Location{Col: 5, Line: 1},
+					Value: ",",
+					Kind:  SymbolKind,
+					Span:  Span{Start: 19, End: 20},
+					Raw:   ",",
+				},
+				{
+					Loc:   Location{Col: 7, Line: 1},
+					Value: "\n",
+					Kind:  StringKind,
+					Span:  Span{Start: 21, End: 26},
+					Raw:   "E'\\n'",
+				},
+				{
+					Loc:   Location{Col: 13, Line: 1},
+					Value: "x",
+					Kind:  StringKind,
+					Span:  Span{Start: 27, End: 32},
+					Raw:   "$$x$$",
+				},
+			},
+			err: nil,
+		},
+		{
+			input: "select a /* unterminated",
+			err:   &Error{Loc: Location{Col: 9}, hint: " after a"},
+		},
 		{
 			input: "SELECT id FROM users;",
 			Tokens: []Token{
@@ -439,26 +1055,36 @@ func TestLex(t *testing.T) {
 					Loc:   Location{Col: 0, Line: 0},
 					Value: string(SelectKeyword),
 					Kind:  KeywordKind,
+					Span:  Span{Start: 0, End: 6},
+					Raw:   "SELECT",
 				},
 				{
 					Loc:   Location{Col: 7, Line: 0},
 					Value: "id",
 					Kind:  IdentifierKind,
+					Span:  Span{Start: 7, End: 9},
+					Raw:   "id",
 				},
 				{
 					Loc:   Location{Col: 10, Line: 0},
 					Value: string(FromKeyword),
 					Kind:  KeywordKind,
+					Span:  Span{Start: 10, End: 14},
+					Raw:   "FROM",
 				},
 				{
 					Loc:   Location{Col: 15, Line: 0},
 					Value: "users",
 					Kind:  IdentifierKind,
+					Span:  Span{Start: 15, End: 20},
+					Raw:   "users",
 				},
 				{
 					Loc:   Location{Col: 20, Line: 0},
 					Value: ";",
 					Kind:  SymbolKind,
+					Span:  Span{Start: 20, End: 21},
+					Raw:   ";",
 				},
 			},
 			err: nil,
@@ -475,3 +1101,63 @@ func TestLex(t *testing.T) {
 		}
 	}
 }
+
+func TestLex_locationAfterNumeric(t *testing.T) {
+	// Where the token after the number starts
+	tests := []struct {
+		input string
+		loc   Location
+	}{
+		{"select 12, a", Location{Col: 9}},
+		{"select 1 /* */ ,2", Location{Col: 15}},
+		{"select 1.5e+3\n, 2", Location{Line: 1, Col: 0}},
+		{"select 'é', 1e5,", Location{Col: 15}},
+	}
+
+	for _, test := range tests {
+		tokens, err := Lex(test.input)
+		assert.Nil(t, err, test.input)
+
+		// The comma is the first token after the number
+		for i, tok := range tokens {
+			if tok.Kind == NumericKind {
+				assert.Equal(t, test.loc, tokens[i+1].Loc, test.input)
+
+				break
+			}
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	source := "-- head\nselect a /* x /* y */ */ from t; -- tail"
+
+	comments, err := Comments(source)
+	assert.Nil(t, err)
+	assert.Equal(t, []*Token{
+		{
+			Value: "-- head",
+			Kind:  CommentKind,
+			Loc:   Location{Line: 0, Col: 0},
+			Span:  Span{Start: 0, End: 7},
+			Raw:   "-- head",
+		},
+		{
+			Value: "/* x /* y */ */",
+			Kind:  CommentKind,
+			Loc:   Location{Line: 1, Col: 9},
+			Span:  Span{Start: 17, End: 32},
+			Raw:   "/* x /* y */ */",
+		},
+		{
+			Value: "-- tail",
+			Kind:  CommentKind,
+			Loc:   Location{Line: 1, Col: 33},
+			Span:  Span{Start: 41, End: 48},
+			Raw:   "-- tail",
+		},
+	}, comments)
+
+	_, err = Comments("select #")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,152 @@
+package lexer
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+const chunkSize = 4096
+
+// lookahead is how far past a token the lexers may read to decide where it
+// ends, longer than any keyword or symbol
+const lookahead = 16
+
+// Lexer yields the tokens of r one at a time, holding only the chunks of
+// input that tokens still in use were lexed from, so large dumps never need
+// to be read whole
+type Lexer struct {
+	reader  io.Reader
+	buffer  string
+	pending []byte
+	// chunk is reused by every read, the buffer copies out of it
+	chunk []byte
+	cur   cursor
+	eof   bool
+
+	// offset is the number of bytes consumed before the buffer
+	offset uint
+
+	peeked *Token
+	last   *Token
+	err    error
+}
+
+func NewLexer(r io.Reader) *Lexer {
+	return &Lexer{reader: r}
+}
+
+// Peek returns the next token without consuming it, or io.EOF once the input
+// is exhausted
+func (l *Lexer) Peek() (*Token, error) {
+	if l.peeked == nil && l.err == nil {
+		l.peeked, l.err = l.next()
+	}
+
+	return l.peeked, l.err
+}
+
+// Next consumes and returns the next token, or io.EOF once the input is
+// exhausted
+func (l *Lexer) Next() (*Token, error) {
+	token, err := l.Peek()
+	l.peeked = nil
+
+	return token, err
+}
+
+func (l *Lexer) next() (*Token, error) {
+	for {
+		if l.cur.pointer >= uint(len(l.buffer)) {
+			if l.eof {
+				return nil, io.EOF
+			}
+
+			if err := l.fill(); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		token, newCursor, ok := lexOne(l.buffer, l.cur)
+
+		// A token running close to the end of the buffer, or one that cannot
+		// be lexed yet, may continue in the input not read so far
+		if !l.eof && (!ok || newCursor.pointer+lookahead >= uint(len(l.buffer))) {
+			if err := l.fill(); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if !ok {
+			return nil, lexError(l.cur.loc, l.last)
+		}
+
+		if token != nil {
+			token.Span = Span{Start: l.offset, End: l.offset + newCursor.pointer}
+			// Tokens share the buffer rather than copying out of it. Every
+			// fill makes a new buffer, so a token keeps at most one chunk
+			// alive
+			token.Raw = l.buffer[:newCursor.pointer]
+		}
+
+		// Drop the consumed input
+		l.buffer = l.buffer[newCursor.pointer:]
+		l.offset += newCursor.pointer
+		newCursor.pointer = 0
+		l.cur = newCursor
+
+		if token == nil {
+			continue
+		}
+		l.last = token
+
+		return token, nil
+	}
+}
+
+// fill appends the next chunk of input to the buffer. Chunks grow with the
+// buffer so a long token is read in a logarithmic number of passes
+func (l *Lexer) fill() error {
+	size := chunkSize
+
+	if len(l.buffer) > size {
+		size = len(l.buffer)
+	}
+
+	if len(l.chunk) < size {
+		l.chunk = make([]byte, size)
+	}
+
+	chunk := l.chunk[:size]
+	n, err := l.reader.Read(chunk)
+
+	if err == io.EOF {
+		l.eof = true
+	} else if err != nil {
+		return err
+	}
+
+	data := append(l.pending, chunk[:n]...)
+	cut := len(data)
+
+	// Hold back a rune split across reads
+	if !l.eof {
+		for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(data[i]) {
+				if !utf8.FullRune(data[i:]) {
+					cut = i
+				}
+
+				break
+			}
+		}
+	}
+
+	l.buffer += string(data[:cut])
+	l.pending = append([]byte(nil), data[cut:]...)
+
+	return nil
+}
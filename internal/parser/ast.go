@@ -4,19 +4,128 @@ import (
 	"github.com/Jadiscke/myown-sql/internal/lexer"
 )
 
-type expressionKind uint
+type ExpressionKind uint
 
 const (
-	LiteralKind expressionKind = iota
+	LiteralKind ExpressionKind = iota
+	BinaryKind
+	UnaryKind
+	CallKind
+	SubqueryKind
+	CaseKind
+	CastKind
+	BetweenKind
+	ListKind
 )
 
-type expression struct {
-	Literal *lexer.Token
-	Kind    expressionKind
+type BinaryExpression struct {
+	A  Expression
+	B  Expression
+	Op lexer.Token
+	// Not is set for negated forms such as IS NOT NULL
+	Not bool `json:",omitempty"`
+	// Escape is the ESCAPE character of LIKE and ILIKE
+	Escape *Expression `json:",omitempty"`
+}
+
+type BetweenExpression struct {
+	Operand Expression
+	Low     Expression
+	High    Expression
+	Not     bool `json:",omitempty"`
+}
+
+type UnaryExpression struct {
+	Operand Expression
+	Op      lexer.Token
+}
+
+type FrameBoundKind uint
+
+const (
+	UnboundedPrecedingKind FrameBoundKind = iota
+	PrecedingKind
+	CurrentRowKind
+	FollowingKind
+	UnboundedFollowingKind
+)
+
+type FrameBound struct {
+	// Offset is the n in n PRECEDING and n FOLLOWING
+	Offset *Expression `json:",omitempty"`
+	Kind   FrameBoundKind
+}
+
+type WindowFrame struct {
+	// Unit is either ROWS or RANGE
+	Unit  lexer.Token
+	Start FrameBound
+	// End is nil unless the frame uses BETWEEN ... AND ...
+	End *FrameBound `json:",omitempty"`
+}
+
+type WindowDefinition struct {
+	PartitionBy []*Expression  `json:",omitempty"`
+	OrderBy     []*OrderByItem `json:",omitempty"`
+	Frame       *WindowFrame   `json:",omitempty"`
+}
+
+type CallExpression struct {
+	Name lexer.Token
+	Args []*Expression `json:",omitempty"`
+	// Distinct is set for aggregates over distinct values, as in
+	// COUNT(DISTINCT x)
+	Distinct bool `json:",omitempty"`
+	// Over is set for window function calls
+	Over *WindowDefinition `json:",omitempty"`
+}
+
+type WhenClause struct {
+	When Expression
+	Then Expression
+}
+
+type CaseExpression struct {
+	// Operand is set for simple CASE x WHEN ..., and nil for searched
+	// CASE WHEN ...
+	Operand *Expression   `json:",omitempty"`
+	Whens   []*WhenClause `json:",omitempty"`
+	Else    *Expression   `json:",omitempty"`
+}
+
+type CastExpression struct {
+	Operand Expression
+	Type    DataType
+	// Op is the CAST keyword or the :: symbol, kept to locate conversion
+	// errors
+	Op lexer.Token
+}
+
+type Expression struct {
+	// Literal is a value, a column name, or the * of a select item or
+	// aggregate argument
+	Literal *lexer.Token `json:",omitempty"`
+	// Table qualifies an identifier or * Literal, as in t.a
+	Table    *lexer.Token       `json:",omitempty"`
+	Binary   *BinaryExpression  `json:",omitempty"`
+	Unary    *UnaryExpression   `json:",omitempty"`
+	Call     *CallExpression    `json:",omitempty"`
+	Subquery *SelectStatement   `json:",omitempty"`
+	Case     *CaseExpression    `json:",omitempty"`
+	Cast     *CastExpression    `json:",omitempty"`
+	Between  *BetweenExpression `json:",omitempty"`
+	// List holds the values of an IN (...) list
+	List []*Expression `json:",omitempty"`
+	Kind ExpressionKind
+}
+
+// Star reports whether e is the * or t.* that stands for every column
+func (e *Expression) Star() bool {
+	return e.Kind == LiteralKind && e.Literal.Kind == lexer.SymbolKind && e.Literal.Value == string(lexer.AsteriskSymbol)
 }
 
 type Ast struct {
-	Statements []*Statement
+	Statements []*Statement `json:",omitempty"`
 }
 
 type AstKind uint
@@ -25,31 +134,184 @@ const (
 	SelectKind AstKind = iota
 	CreateTableKind
 	InsertKind
+	AlterTableKind
+	CreateSchemaKind
+	DropSchemaKind
+	AttachKind
+	CreateViewKind
+	RefreshViewKind
+	DropViewKind
+	CreateTriggerKind
 )
 
 type Statement struct {
-	SelectStatement      *SelectStatement
-	CreateTableStatement *CreateTableStatement
-	InsertStatement      *InsertStatement
-	Kind                 AstKind
+	SelectStatement        *SelectStatement        `json:",omitempty"`
+	CreateTableStatement   *CreateTableStatement   `json:",omitempty"`
+	InsertStatement        *InsertStatement        `json:",omitempty"`
+	AlterTableStatement    *AlterTableStatement    `json:",omitempty"`
+	CreateSchemaStatement  *CreateSchemaStatement  `json:",omitempty"`
+	DropSchemaStatement    *DropSchemaStatement    `json:",omitempty"`
+	AttachStatement        *AttachStatement        `json:",omitempty"`
+	CreateViewStatement    *CreateViewStatement    `json:",omitempty"`
+	RefreshViewStatement   *RefreshViewStatement   `json:",omitempty"`
+	DropViewStatement      *DropViewStatement      `json:",omitempty"`
+	CreateTriggerStatement *CreateTriggerStatement `json:",omitempty"`
+	Kind                   AstKind
 }
 
 type InsertStatement struct {
+	With *WithClause `json:",omitempty"`
+	// Schema qualifies the table name, as in INSERT INTO s.t
+	Schema *lexer.Token `json:",omitempty"`
 	Table  lexer.Token
-	Values *[]*expression
+	Values *[]*Expression `json:",omitempty"`
+}
+
+type DataType struct {
+	Name lexer.Token
+	// Params holds the numeric type modifiers, e.g. the length in VARCHAR(n)
+	// or the precision and scale in NUMERIC(p,s)
+	Params []*lexer.Token `json:",omitempty"`
 }
 
-type columnDefinition struct {
+type ColumnDefinition struct {
 	Name     lexer.Token
-	Datatype lexer.Token
+	Datatype DataType
+	Default  *Expression `json:",omitempty"`
 }
 
 type CreateTableStatement struct {
+	Schema *lexer.Token `json:",omitempty"`
+	Name   lexer.Token
+	Cols   *[]*ColumnDefinition `json:",omitempty"`
+}
+
+type AlterTableActionKind uint
+
+const (
+	AddColumnKind AlterTableActionKind = iota
+	DropColumnKind
+	RenameColumnKind
+	RenameTableKind
+)
+
+type AlterTableStatement struct {
+	Schema *lexer.Token `json:",omitempty"`
+	Name   lexer.Token
+	Kind   AlterTableActionKind
+	// AddColumn is the new column for ADD COLUMN
+	AddColumn *ColumnDefinition `json:",omitempty"`
+	// Column is the existing column for DROP COLUMN and RENAME COLUMN
+	Column lexer.Token
+	// NewName is the target of RENAME COLUMN and RENAME TO
+	NewName lexer.Token
+}
+
+type CreateSchemaStatement struct {
+	Name lexer.Token
+}
+
+type DropSchemaStatement struct {
+	Name lexer.Token
+}
+
+// AttachStatement opens the database in File as the schema Name
+type AttachStatement struct {
+	File lexer.Token
+	Name lexer.Token
+}
+
+type CreateViewStatement struct {
+	Schema *lexer.Token `json:",omitempty"`
+	Name   lexer.Token
+	// Columns renames the columns of Query
+	Columns []*lexer.Token `json:",omitempty"`
+	// Materialized views store the rows of Query like a table, until
+	// REFRESH MATERIALIZED VIEW computes them again
+	Materialized bool             `json:",omitempty"`
+	Query        *SelectStatement `json:",omitempty"`
+}
+
+// RefreshViewStatement is REFRESH MATERIALIZED VIEW
+type RefreshViewStatement struct {
+	Schema *lexer.Token `json:",omitempty"`
+	Name   lexer.Token
+}
+
+type DropViewStatement struct {
+	Schema       *lexer.Token `json:",omitempty"`
+	Name         lexer.Token
+	Materialized bool `json:",omitempty"`
+}
+
+// CreateTriggerStatement runs Body for each row an INSERT, UPDATE or
+// DELETE on Table changes. Body and When refer to the row as NEW and OLD
+type CreateTriggerStatement struct {
 	Name lexer.Token
-	Cols *[]*columnDefinition
+	// Timing is BEFORE or AFTER
+	Timing lexer.Token
+	// Event is INSERT, UPDATE or DELETE
+	Event  lexer.Token
+	Schema *lexer.Token `json:",omitempty"`
+	Table  lexer.Token
+	When   *Expression  `json:",omitempty"`
+	Body   []*Statement `json:",omitempty"`
+}
+
+type FromItemKind uint
+
+const (
+	TableFromKind FromItemKind = iota
+	SubqueryFromKind
+)
+
+type FromItem struct {
+	Schema   *lexer.Token     `json:",omitempty"`
+	Table    *lexer.Token     `json:",omitempty"`
+	Subquery *SelectStatement `json:",omitempty"`
+	Alias    *lexer.Token     `json:",omitempty"`
+	Kind     FromItemKind
+}
+
+type CommonTableExpression struct {
+	Name    lexer.Token
+	Columns []*lexer.Token   `json:",omitempty"`
+	Query   *SelectStatement `json:",omitempty"`
+}
+
+type WithClause struct {
+	Recursive bool                     `json:",omitempty"`
+	Ctes      []*CommonTableExpression `json:",omitempty"`
+}
+
+type OrderByItem struct {
+	Exp  *Expression `json:",omitempty"`
+	Desc bool        `json:",omitempty"`
+}
+
+// SetOperation combines the results of two queries with UNION, INTERSECT
+// or EXCEPT
+type SetOperation struct {
+	Op    lexer.Token
+	All   bool             `json:",omitempty"`
+	Left  *SelectStatement `json:",omitempty"`
+	Right *SelectStatement `json:",omitempty"`
 }
 
 type SelectStatement struct {
-	Item []*expression
-	From lexer.Token
+	With     *WithClause `json:",omitempty"`
+	Distinct bool        `json:",omitempty"`
+	// DistinctOn holds the expressions of DISTINCT ON (...), which implies
+	// Distinct
+	DistinctOn []*Expression `json:",omitempty"`
+	Item       []*Expression `json:",omitempty"`
+	From       *FromItem     `json:",omitempty"`
+	Where      *Expression   `json:",omitempty"`
+	// SetOperation is set for compound queries, in which case Item, From
+	// and Where are empty and OrderBy, Limit and Offset apply to the
+	// compound result
+	SetOperation *SetOperation  `json:",omitempty"`
+	OrderBy      []*OrderByItem `json:",omitempty"`
+	Limit        *Expression    `json:",omitempty"`
+	Offset       *Expression    `json:",omitempty"`
 }
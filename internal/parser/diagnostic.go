@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Jadiscke/myown-sql/internal/lexer"
+)
+
+type Severity uint
+
+const (
+	ErrorSeverity Severity = iota
+	WarningSeverity
+)
+
+func (s Severity) String() string {
+	if s == WarningSeverity {
+		return "warning"
+	}
+
+	return "error"
+}
+
+// Diagnostic is a problem found in the source, covering Start up to End
+type Diagnostic struct {
+	Severity Severity
+	Start    lexer.Location
+	End      lexer.Location
+	Message  string
+	Got      string
+	// Suggestion is the keyword the offending word most likely meant
+	Suggestion lexer.Keyword
+}
+
+func (d Diagnostic) Error() string {
+	msg := fmt.Sprintf("[%d,%d]: %s", d.Start.Line, d.Start.Col, d.Message)
+
+	if d.Got != "" {
+		msg += ", got: " + d.Got
+	}
+
+	if d.Suggestion != "" {
+		msg += ", did you mean " + strings.ToUpper(string(d.Suggestion)) + "?"
+	}
+
+	return msg
+}
+
+// editDistance counts the single character insertions, deletions,
+// substitutions and transpositions turning a into b
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	d := make([][]int, len(s)+1)
+
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(s)][len(t)]
+}
+
+// suggestKeyword finds the keyword closest to a misspelled word, if any is
+// close enough to be a likely typo
+func suggestKeyword(word string) lexer.Keyword {
+	var suggestion lexer.Keyword
+
+	best := len([]rune(word))/3 + 1
+
+	for _, kw := range lexer.Keywords() {
+		if distance := editDistance(word, string(kw)); distance < best {
+			best = distance
+			suggestion = kw
+		}
+	}
+
+	return suggestion
+}
@@ -0,0 +1,89 @@
+package parser
+
+import "fmt"
+
+// The kinds are encoded by name, so JSON ASTs stay readable and don't
+// depend on the order of the constants
+
+var expressionKindNames = []string{"literal", "binary", "unary", "call", "subquery", "case", "cast", "between", "list"}
+
+var astKindNames = []string{"select", "create_table", "insert", "alter_table", "create_schema", "drop_schema", "attach", "create_view", "refresh_view", "drop_view", "create_trigger"}
+
+var alterTableActionKindNames = []string{"add_column", "drop_column", "rename_column", "rename_table"}
+
+var fromItemKindNames = []string{"table", "subquery"}
+
+var frameBoundKindNames = []string{"unbounded_preceding", "preceding", "current_row", "following", "unbounded_following"}
+
+func kindText(names []string, kind uint) ([]byte, error) {
+	if kind >= uint(len(names)) {
+		return nil, fmt.Errorf("Unknown kind %d", kind)
+	}
+
+	return []byte(names[kind]), nil
+}
+
+func kindFromText(names []string, text []byte) (uint, error) {
+	for i, name := range names {
+		if name == string(text) {
+			return uint(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("Unknown kind %q", text)
+}
+
+func (k ExpressionKind) MarshalText() ([]byte, error) {
+	return kindText(expressionKindNames, uint(k))
+}
+
+func (k *ExpressionKind) UnmarshalText(text []byte) error {
+	kind, err := kindFromText(expressionKindNames, text)
+	*k = ExpressionKind(kind)
+
+	return err
+}
+
+func (k AstKind) MarshalText() ([]byte, error) {
+	return kindText(astKindNames, uint(k))
+}
+
+func (k *AstKind) UnmarshalText(text []byte) error {
+	kind, err := kindFromText(astKindNames, text)
+	*k = AstKind(kind)
+
+	return err
+}
+
+func (k AlterTableActionKind) MarshalText() ([]byte, error) {
+	return kindText(alterTableActionKindNames, uint(k))
+}
+
+func (k *AlterTableActionKind) UnmarshalText(text []byte) error {
+	kind, err := kindFromText(alterTableActionKindNames, text)
+	*k = AlterTableActionKind(kind)
+
+	return err
+}
+
+func (k FromItemKind) MarshalText() ([]byte, error) {
+	return kindText(fromItemKindNames, uint(k))
+}
+
+func (k *FromItemKind) UnmarshalText(text []byte) error {
+	kind, err := kindFromText(fromItemKindNames, text)
+	*k = FromItemKind(kind)
+
+	return err
+}
+
+func (k FrameBoundKind) MarshalText() ([]byte, error) {
+	return kindText(frameBoundKindNames, uint(k))
+}
+
+func (k *FrameBoundKind) UnmarshalText(text []byte) error {
+	kind, err := kindFromText(frameBoundKindNames, text)
+	*k = FrameBoundKind(kind)
+
+	return err
+}
@@ -1,7 +1,10 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"strconv"
 
 	"github.com/Jadiscke/myown-sql/internal/lexer"
 )
@@ -20,35 +23,144 @@ func tokenFromSymbol(s lexer.Symbol) lexer.Token {
 	}
 }
 
-func expectToken(tokens []*lexer.Token, cursor uint, t lexer.Token) bool {
-	if cursor >= uint(len(tokens)) {
+func (p *parser) expectToken(cursor uint, t lexer.Token) bool {
+	if cursor >= uint(len(p.tokens)) {
 		return false
 	}
 
-	return t.Equals(tokens[cursor])
+	return t.Equals(p.tokens[cursor])
 }
 
-func helpMessage(tokens []*lexer.Token, cursor uint, msg string) {
-	var c *lexer.Token
+// parser holds the tokens being parsed and the furthest failure seen while
+// trying alternatives, which is where a broken statement most likely went
+// wrong
+type parser struct {
+	tokens []*lexer.Token
 
-	if cursor < uint(len(tokens)) {
-		c = tokens[cursor]
-	} else {
-		c = tokens[cursor-1]
+	failed         bool
+	failureCursor  uint
+	failureMessage string
+}
+
+// helpMessage records why parsing failed at cursor, unless some alternative
+// already got further
+func (p *parser) helpMessage(cursor uint, msg string) {
+	if p.failed && cursor <= p.failureCursor {
+		return
+	}
+
+	p.failed = true
+	p.failureCursor = cursor
+	p.failureMessage = msg
+}
+
+// diagnostic reports the furthest failure
+func (p *parser) diagnostic() Diagnostic {
+	d := Diagnostic{
+		Severity: ErrorSeverity,
+		Message:  p.failureMessage,
+	}
+
+	if p.failureCursor >= uint(len(p.tokens)) {
+		d.Start = p.tokens[len(p.tokens)-1].End()
+		d.End = d.Start
+		d.Got = "end of input"
+
+		return d
+	}
+
+	t := p.tokens[p.failureCursor]
+	d.Start = t.Loc
+	d.End = t.End()
+	d.Got = t.Value
+
+	if t.Kind == lexer.IdentifierKind {
+		d.Suggestion = suggestKeyword(t.Value)
+	}
+
+	return d
+}
+
+// Splitter finds the semicolons that end statements, passing over the ones
+// inside a trigger's BEGIN ... END body. Any other BEGIN, as in a dump's
+// BEGIN TRANSACTION, is an ordinary statement
+type Splitter struct {
+	// seen counts the tokens of the statement so far
+	seen    int
+	create  bool
+	trigger bool
+	depth   int
+}
+
+// Ends reports whether t, the next token of the source, ends a statement
+func (s *Splitter) Ends(t *lexer.Token) bool {
+	keyword := lexer.Keyword("")
+
+	if t.Kind == lexer.KeywordKind {
+		keyword = lexer.Keyword(t.Value)
+	}
+
+	switch s.seen {
+	case 0:
+		s.create = keyword == lexer.CreateKeyword
+	case 1:
+		s.trigger = s.create && keyword == lexer.TriggerKeyword
+	}
+
+	s.seen++
+
+	if s.trigger {
+		switch keyword {
+		case lexer.BeginKeyword:
+			s.depth++
+		case lexer.CaseKeyword:
+			// CASE only needs counting for the END it shares with BEGIN
+			if s.depth > 0 {
+				s.depth++
+			}
+		case lexer.EndKeyword:
+			if s.depth > 0 {
+				s.depth--
+			}
+		}
+	}
+
+	semicolon := tokenFromSymbol(lexer.SemicolonSymbol)
+
+	if s.depth > 0 || !t.Equals(&semicolon) {
+		return false
 	}
 
-	fmt.Printf("[%d,%d]: %s, got: %s\n", c.Loc.Line, c.Loc.Col, msg, c.Value)
+	*s = Splitter{}
+
+	return true
+}
+
+// skipStatement recovers from a failure by skipping past the semicolon that
+// ends the statement
+func (p *parser) skipStatement(cursor uint) uint {
+	var s Splitter
+
+	for cursor < uint(len(p.tokens)) {
+		end := s.Ends(p.tokens[cursor])
+		cursor++
+
+		if end {
+			break
+		}
+	}
 
+	return cursor
 }
 
-func parseToken(tokens []*lexer.Token, initialCursor uint, kind lexer.TokenKind) (*lexer.Token, uint, bool) {
+func (p *parser) parseToken(initialCursor uint, kind lexer.TokenKind) (*lexer.Token, uint, bool) {
 	cursor := initialCursor
 
-	if cursor >= uint(len(tokens)) {
+	if cursor >= uint(len(p.tokens)) {
 		return nil, cursor, false
 	}
 
-	current := tokens[cursor]
+	current := p.tokens[cursor]
 
 	if current.Kind == kind {
 		return current, cursor + 1, true
@@ -57,20 +169,66 @@ func parseToken(tokens []*lexer.Token, initialCursor uint, kind lexer.TokenKind)
 	return nil, initialCursor, false
 }
 
-func parseExpression(tokens []*lexer.Token, initialCursor uint, _ lexer.Token) (*expression, uint, bool) {
+// parseIdentifier parses a name, quoted or not. Non-reserved keywords are
+// names too, so a column can be called date or rows
+func (p *parser) parseIdentifier(initialCursor uint) (*lexer.Token, uint, bool) {
+	if id, newCursor, ok := p.parseToken(initialCursor, lexer.IdentifierKind); ok {
+		return id, newCursor, true
+	}
+
+	if id, newCursor, ok := p.parseToken(initialCursor, lexer.QuotedIdentifierKind); ok {
+		return id, newCursor, true
+	}
+
+	kw, newCursor, ok := p.parseToken(initialCursor, lexer.KeywordKind)
+
+	if !ok || lexer.Keyword(kw.Value).Reserved() {
+		return nil, initialCursor, false
+	}
+
+	id := *kw
+	id.Kind = lexer.IdentifierKind
+
+	return &id, newCursor, true
+}
+
+func (p *parser) parseLiteralExpression(initialCursor uint) (*Expression, uint, bool) {
 	cursor := initialCursor
 
+	if id, newCursor, ok := p.parseIdentifier(cursor); ok {
+		if !p.expectToken(newCursor, tokenFromSymbol(lexer.DotSymbol)) {
+			return &Expression{
+				Literal: id,
+				Kind:    LiteralKind,
+			}, newCursor, true
+		}
+
+		column, columnCursor, ok := p.parseIdentifier(newCursor + 1)
+
+		if !ok {
+			p.helpMessage(columnCursor, "Expected column name")
+			return nil, initialCursor, false
+		}
+
+		return &Expression{
+			Literal: column,
+			Table:   id,
+			Kind:    LiteralKind,
+		}, columnCursor, true
+	}
+
 	kinds := []lexer.TokenKind{
-		lexer.IdentifierKind,
 		lexer.NumericKind,
 		lexer.StringKind,
+		lexer.BoolKind,
+		lexer.NullKind,
 	}
 
 	for _, kind := range kinds {
-		t, newCursor, ok := parseToken(tokens, cursor, kind)
+		t, newCursor, ok := p.parseToken(cursor, kind)
 
 		if ok {
-			return &expression{
+			return &Expression{
 				Literal: t,
 				Kind:    LiteralKind,
 			}, newCursor, true
@@ -80,149 +238,2820 @@ func parseExpression(tokens []*lexer.Token, initialCursor uint, _ lexer.Token) (
 	return nil, initialCursor, false
 }
 
-func parseExpressions(tokens []*lexer.Token, initialCursor uint, delimiters []lexer.Token) (*[]*expression, uint, bool) {
+// functionKeywords are keywords that are called like ordinary functions
+var functionKeywords = []lexer.Keyword{
+	lexer.CoalesceKeyword,
+	lexer.NullifKeyword,
+	lexer.RowNumberKeyword,
+	lexer.DateTruncKeyword,
+}
+
+func (p *parser) parseFunctionName(initialCursor uint) (*lexer.Token, uint, bool) {
+	if name, newCursor, ok := p.parseToken(initialCursor, lexer.IdentifierKind); ok {
+		return name, newCursor, true
+	}
+
+	for _, kw := range functionKeywords {
+		if p.expectToken(initialCursor, tokenFromKeyword(kw)) {
+			return p.tokens[initialCursor], initialCursor + 1, true
+		}
+	}
+
+	return nil, initialCursor, false
+}
+
+// parseExtractExpression parses EXTRACT(field FROM expression) into a call
+// whose first argument is the field
+func (p *parser) parseExtractExpression(initialCursor uint) (*Expression, uint, bool) {
 	cursor := initialCursor
 
-	exps := []*expression{}
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.ExtractKeyword)) {
+		return nil, initialCursor, false
+	}
+
+	name := p.tokens[cursor]
+	cursor++
+
+	// Without a paren extract is an ordinary name
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.LeftParenSymbol)) {
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	field, newCursor, ok := p.parseIdentifier(cursor)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected field to EXTRACT")
+
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.FromKeyword)) {
+		p.helpMessage(cursor, "Expected FROM")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	source, newCursor, ok := p.parseExpression(cursor, 0)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected expression to EXTRACT from")
+
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.RightParenSymbol)) {
+		p.helpMessage(cursor, "Expected right paren")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	return &Expression{
+		Call: &CallExpression{
+			Name: *name,
+			Args: []*Expression{
+				{
+					Literal: field,
+					Kind:    LiteralKind,
+				},
+				source,
+			},
+		},
+		Kind: CallKind,
+	}, cursor, true
+}
+
+// parseCaseExpression parses both the searched
+// CASE WHEN cond THEN x ... [ELSE y] END and the simple
+// CASE operand WHEN value THEN x ... [ELSE y] END forms
+func (p *parser) parseCaseExpression(initialCursor uint) (*Expression, uint, bool) {
+	cursor := initialCursor
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.CaseKeyword)) {
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	cse := CaseExpression{}
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.WhenKeyword)) {
+		operand, newCursor, ok := p.parseExpression(cursor, 0)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected WHEN or CASE operand")
 
-outer:
-	for {
-		if cursor >= uint(len(tokens)) {
 			return nil, initialCursor, false
 		}
 
-		current := tokens[cursor]
+		cse.Operand = operand
 
-		for _, delimiter := range delimiters {
-			if delimiter.Equals(current) {
-				break outer
-			}
+		cursor = newCursor
+	}
+
+	for p.expectToken(cursor, tokenFromKeyword(lexer.WhenKeyword)) {
+		cursor++
+
+		when, newCursor, ok := p.parseExpression(cursor, 0)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected WHEN expression")
+
+			return nil, initialCursor, false
 		}
 
-		if len(exps) > 0 {
-			if !expectToken(tokens, cursor, tokenFromSymbol(lexer.CommaSymbol)) {
-				helpMessage(tokens, cursor, "Expected comma")
+		cursor = newCursor
 
-				return nil, initialCursor, false
-			}
+		if !p.expectToken(cursor, tokenFromKeyword(lexer.ThenKeyword)) {
+			p.helpMessage(cursor, "Expected THEN")
 
-			cursor++
+			return nil, initialCursor, false
 		}
 
-		exp, newCursor, ok := parseExpression(tokens, cursor, tokenFromSymbol(lexer.CommaSymbol))
+		cursor++
+
+		then, newCursor, ok := p.parseExpression(cursor, 0)
 
 		if !ok {
-			helpMessage(tokens, cursor, "Expected expression")
+			p.helpMessage(cursor, "Expected THEN expression")
+
 			return nil, initialCursor, false
 		}
 
 		cursor = newCursor
 
-		exps = append(exps, exp)
+		cse.Whens = append(cse.Whens, &WhenClause{
+			When: *when,
+			Then: *then,
+		})
 	}
 
-	return &exps, cursor, true
+	if len(cse.Whens) == 0 {
+		p.helpMessage(cursor, "Expected WHEN")
 
-}
+		return nil, initialCursor, false
+	}
 
-func Parse(source string) (*Ast, error) {
-	tokens, err := lexer.Lex(source)
+	if p.expectToken(cursor, tokenFromKeyword(lexer.ElseKeyword)) {
+		cursor++
 
-	if err != nil {
-		return nil, err
-	}
+		els, newCursor, ok := p.parseExpression(cursor, 0)
 
-	a := Ast{}
+		if !ok {
+			p.helpMessage(cursor, "Expected ELSE expression")
 
-	cursor := uint(0)
+			return nil, initialCursor, false
+		}
+
+		cse.Else = els
+
+		cursor = newCursor
+	}
 
-	for cursor < uint(len(tokens)) {
-		statement, newCursor, ok := parseStatement(tokens, cursor)
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.EndKeyword)) {
+		p.helpMessage(cursor, "Expected END")
+
+		return nil, initialCursor, false
 	}
+
+	cursor++
+
+	return &Expression{
+		Case: &cse,
+		Kind: CaseKind,
+	}, cursor, true
 }
 
-func parseStatement(tokens []*lexer.Token, initialCursor uint) (*Statement, uint, bool) {
+// parseCastExpression parses CAST(expression AS type)
+func (p *parser) parseCastExpression(initialCursor uint) (*Expression, uint, bool) {
 	cursor := initialCursor
 
-	semicolonToken := tokenFromSymbol(lexer.SemicolonSymbol)
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.CastKeyword)) {
+		return nil, initialCursor, false
+	}
 
-	slct, newCursor, ok := parseSelectStatement(tokens, cursor, semicolonToken)
+	op := p.tokens[cursor]
+	cursor++
 
-	if ok {
-		return &Statement{
-			Kind:            SelectKind,
-			SelectStatement: slct,
-		}, newCursor, true
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.LeftParenSymbol)) {
+		p.helpMessage(cursor, "Expected left paren after CAST")
+
+		return nil, initialCursor, false
 	}
 
-	inst, newCursor, ok := parseInsertStatement(tokens, cursor, semicolonToken)
+	cursor++
 
-	if ok {
-		return &Statement{
-			Kind:            InsertKind,
-			InsertStatement: inst,
-		}, newCursor, true
+	operand, newCursor, ok := p.parseExpression(cursor, 0)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected expression to CAST")
+
+		return nil, initialCursor, false
 	}
 
-	createTbl, newCursor, ok := parseCreateTableStatement(tokens, cursor, semicolonToken)
+	cursor = newCursor
 
-	if ok {
-		return &Statement{
-			Kind:                 CreateTableKind,
-			CreateTableStatement: createTbl,
-		}, newCursor, true
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.AsKeyord)) {
+		p.helpMessage(cursor, "Expected AS")
+
+		return nil, initialCursor, false
 	}
 
-	return nil, initialCursor, false
+	cursor++
+
+	dt, newCursor, ok := p.parseDatatype(cursor)
+
+	if !ok {
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.RightParenSymbol)) {
+		p.helpMessage(cursor, "Expected right paren")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
 
+	return &Expression{
+		Cast: &CastExpression{
+			Operand: *operand,
+			Type:    *dt,
+			Op:      *op,
+		},
+		Kind: CastKind,
+	}, cursor, true
 }
 
-func parseSelectStatement(tokens []*lexer.Token, initialCursor uint, delimiter lexer.Token) (*SelectStatement, uint, bool) {
+func (p *parser) parseCallExpression(initialCursor uint) (*Expression, uint, bool) {
 	cursor := initialCursor
 
-	if !expectToken(tokens, cursor, tokenFromKeyword(lexer.SelectKeyword)) {
+	name, newCursor, ok := p.parseFunctionName(cursor)
+
+	if !ok {
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.LeftParenSymbol)) {
 		return nil, initialCursor, false
 	}
 
 	cursor++
 
-	slct := SelectStatement{}
+	distinct := p.expectToken(cursor, tokenFromKeyword(lexer.DistinctKeyword))
+
+	if distinct || p.expectToken(cursor, tokenFromKeyword(lexer.AllKeyword)) {
+		cursor++
+	}
 
-	exps, newCursor, ok := parseExpressions(tokens, cursor, []lexer.Token{delimiter, tokenFromKeyword(lexer.FromKeyword)})
+	args, newCursor, ok := p.parseItems(cursor, []lexer.Token{tokenFromSymbol(lexer.RightParenSymbol)})
 
 	if !ok {
 		return nil, initialCursor, false
 	}
 
-	slct.Item = *exps
+	if distinct && len(*args) == 0 {
+		p.helpMessage(cursor, "Expected expression after DISTINCT")
+
+		return nil, initialCursor, false
+	}
+
 	cursor = newCursor
 
-	if expectToken(tokens, cursor, tokenFromKeyword(lexer.FromKeyword)) {
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.RightParenSymbol)) {
+		p.helpMessage(cursor, "Expected right paren")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	call := CallExpression{
+		Name:     *name,
+		Args:     *args,
+		Distinct: distinct,
+	}
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.OverKeyword)) {
 		cursor++
 
-		from, newCursor, ok := parseToken(tokens, cursor, lexer.IdentifierKind)
+		over, newCursor, ok := p.parseWindowDefinition(cursor)
 
 		if !ok {
-			helpMessage(tokens, cursor, "Expected FROM token")
-
 			return nil, initialCursor, false
 		}
 
-		slct.From = *from
+		call.Over = over
 
 		cursor = newCursor
 	}
-	return &slct, cursor, true
+
+	return &Expression{
+		Call: &call,
+		Kind: CallKind,
+	}, cursor, true
 }
 
-func parseInsertStatement(tokens []*lexer.Token, initialCursor uint, delimiter lexer.Token) (*InsertStatement, uint, bool) {
+// parseWindowDefinition parses
+// ([PARTITION BY ...] [ORDER BY ...] [{ROWS | RANGE} frame])
+func (p *parser) parseWindowDefinition(initialCursor uint) (*WindowDefinition, uint, bool) {
 	cursor := initialCursor
 
-	// Look for INSERT
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.LeftParenSymbol)) {
+		p.helpMessage(cursor, "Expected left paren after OVER")
 
-	if !expectToken(tokens, cursor, tokenFromKeyword(lexer.InsertKeyword)) {
 		return nil, initialCursor, false
 	}
 
-	return nil, initialCursor, false
+	cursor++
+
+	window := WindowDefinition{}
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.PartitionKeyword)) {
+		cursor++
+
+		if !p.expectToken(cursor, tokenFromKeyword(lexer.ByKeyword)) {
+			p.helpMessage(cursor, "Expected BY")
+
+			return nil, initialCursor, false
+		}
+
+		cursor++
+
+		exps, newCursor, ok := p.parseExpressions(cursor, []lexer.Token{
+			tokenFromSymbol(lexer.RightParenSymbol),
+			tokenFromKeyword(lexer.OrderKeyword),
+			tokenFromKeyword(lexer.RowsKeyword),
+			tokenFromKeyword(lexer.RangeKeyword),
+		})
+
+		if !ok {
+			return nil, initialCursor, false
+		}
+
+		if len(*exps) == 0 {
+			p.helpMessage(cursor, "Expected PARTITION BY expression")
+
+			return nil, initialCursor, false
+		}
+
+		window.PartitionBy = *exps
+
+		cursor = newCursor
+	}
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.OrderKeyword)) {
+		orderBy, newCursor, ok := p.parseOrderBy(cursor)
+
+		if !ok {
+			return nil, initialCursor, false
+		}
+
+		window.OrderBy = orderBy
+
+		cursor = newCursor
+	}
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.RowsKeyword)) ||
+		p.expectToken(cursor, tokenFromKeyword(lexer.RangeKeyword)) {
+		frame, newCursor, ok := p.parseWindowFrame(cursor)
+
+		if !ok {
+			return nil, initialCursor, false
+		}
+
+		window.Frame = frame
+
+		cursor = newCursor
+	}
+
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.RightParenSymbol)) {
+		p.helpMessage(cursor, "Expected right paren")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	return &window, cursor, true
+}
+
+func (p *parser) parseWindowFrame(initialCursor uint) (*WindowFrame, uint, bool) {
+	cursor := initialCursor
+
+	frame := WindowFrame{Unit: *p.tokens[cursor]}
+
+	cursor++
+
+	between := p.expectToken(cursor, tokenFromKeyword(lexer.BetweenKeyword))
+
+	if between {
+		cursor++
+	}
+
+	start, newCursor, ok := p.parseFrameBound(cursor)
+
+	if !ok {
+		return nil, initialCursor, false
+	}
+
+	if start.Kind == UnboundedFollowingKind {
+		p.helpMessage(cursor, "Frame start cannot be UNBOUNDED FOLLOWING")
+
+		return nil, initialCursor, false
+	}
+
+	frame.Start = *start
+
+	cursor = newCursor
+
+	if !between {
+		return &frame, cursor, true
+	}
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.AndKeyword)) {
+		p.helpMessage(cursor, "Expected AND")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	end, newCursor, ok := p.parseFrameBound(cursor)
+
+	if !ok {
+		return nil, initialCursor, false
+	}
+
+	if end.Kind == UnboundedPrecedingKind {
+		p.helpMessage(cursor, "Frame end cannot be UNBOUNDED PRECEDING")
+
+		return nil, initialCursor, false
+	}
+
+	frame.End = end
+
+	cursor = newCursor
+
+	return &frame, cursor, true
+}
+
+// parseFrameBound parses UNBOUNDED PRECEDING, n PRECEDING, CURRENT ROW,
+// n FOLLOWING or UNBOUNDED FOLLOWING
+func (p *parser) parseFrameBound(initialCursor uint) (*FrameBound, uint, bool) {
+	cursor := initialCursor
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.CurrentKeyword)) {
+		cursor++
+
+		if !p.expectToken(cursor, tokenFromKeyword(lexer.RowKeyword)) {
+			p.helpMessage(cursor, "Expected ROW")
+
+			return nil, initialCursor, false
+		}
+
+		cursor++
+
+		return &FrameBound{Kind: CurrentRowKind}, cursor, true
+	}
+
+	bound := FrameBound{}
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.UnboundedKeyword)) {
+		cursor++
+	} else {
+		// The offset must not swallow the AND of BETWEEN ... AND ...
+		offset, newCursor, ok := p.parseExpression(cursor, andBindingPower+1)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected frame bound")
+
+			return nil, initialCursor, false
+		}
+
+		bound.Offset = offset
+
+		cursor = newCursor
+	}
+
+	switch {
+	case p.expectToken(cursor, tokenFromKeyword(lexer.PrecedingKeyword)):
+		bound.Kind = PrecedingKind
+
+		if bound.Offset == nil {
+			bound.Kind = UnboundedPrecedingKind
+		}
+	case p.expectToken(cursor, tokenFromKeyword(lexer.FollowingKeyword)):
+		bound.Kind = FollowingKind
+
+		if bound.Offset == nil {
+			bound.Kind = UnboundedFollowingKind
+		}
+	default:
+		p.helpMessage(cursor, "Expected PRECEDING or FOLLOWING")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	return &bound, cursor, true
+}
+
+// Binding powers, from loosest to tightest
+const (
+	orBindingPower uint = iota + 1
+	andBindingPower
+	notBindingPower
+	isBindingPower
+	comparisonBindingPower
+	predicateBindingPower
+	concatBindingPower
+	castBindingPower
+)
+
+type binaryOperator struct {
+	Token        lexer.Token
+	BindingPower uint
+}
+
+var binaryOperators = []binaryOperator{
+	{tokenFromKeyword(lexer.OrKeyword), orBindingPower},
+	{tokenFromKeyword(lexer.AndKeyword), andBindingPower},
+	{tokenFromKeyword(lexer.IsKeyword), isBindingPower},
+	{tokenFromSymbol(lexer.EqSymbol), comparisonBindingPower},
+	{tokenFromSymbol(lexer.NeqSymbol), comparisonBindingPower},
+	{tokenFromSymbol(lexer.NeqBangSymbol), comparisonBindingPower},
+	{tokenFromSymbol(lexer.LtSymbol), comparisonBindingPower},
+	{tokenFromSymbol(lexer.LteSymbol), comparisonBindingPower},
+	{tokenFromSymbol(lexer.GtSymbol), comparisonBindingPower},
+	{tokenFromSymbol(lexer.GteSymbol), comparisonBindingPower},
+	{tokenFromKeyword(lexer.InKeyword), predicateBindingPower},
+	{tokenFromKeyword(lexer.LikeKeyword), predicateBindingPower},
+	{tokenFromKeyword(lexer.IlikeKeyword), predicateBindingPower},
+	{tokenFromKeyword(lexer.BetweenKeyword), predicateBindingPower},
+	{tokenFromSymbol(lexer.ConcatSymbol), concatBindingPower},
+	{tokenFromSymbol(lexer.CastSymbol), castBindingPower},
+}
+
+// negatableOperators may be preceded by NOT, as in NOT IN
+var negatableOperators = []lexer.Keyword{
+	lexer.InKeyword,
+	lexer.LikeKeyword,
+	lexer.IlikeKeyword,
+	lexer.BetweenKeyword,
+}
+
+func isNegatable(t *lexer.Token) bool {
+	for _, kw := range negatableOperators {
+		if op := tokenFromKeyword(kw); op.Equals(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func bindingPower(t *lexer.Token) (uint, bool) {
+	for _, op := range binaryOperators {
+		if op.Token.Equals(t) {
+			return op.BindingPower, true
+		}
+	}
+
+	return 0, false
+}
+
+// BindingPower is how tightly the operator at the top of the expression
+// binds, which decides where it needs parens when printed. Literals, calls
+// and other self-delimited expressions bind tightest
+func (e *Expression) BindingPower() uint {
+	switch e.Kind {
+	case BinaryKind:
+		// IS [NOT] DISTINCT FROM keeps DISTINCT as its operator
+		if op := tokenFromKeyword(lexer.DistinctKeyword); op.Equals(&e.Binary.Op) {
+			return isBindingPower
+		}
+
+		bp, _ := bindingPower(&e.Binary.Op)
+
+		return bp
+	case UnaryKind:
+		if op := tokenFromKeyword(lexer.NotKeyword); op.Equals(&e.Unary.Op) {
+			return notBindingPower
+		}
+	case BetweenKind:
+		return predicateBindingPower
+	case CastKind:
+		if op := tokenFromSymbol(lexer.CastSymbol); op.Equals(&e.Cast.Op) {
+			return castBindingPower
+		}
+	}
+
+	return castBindingPower + 1
+}
+
+// parseSubquery parses a parenthesized SELECT
+func (p *parser) parseSubquery(initialCursor uint) (*SelectStatement, uint, bool) {
+	cursor := initialCursor
+
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.LeftParenSymbol)) {
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	slct, newCursor, ok := p.parseSelectStatement(cursor, tokenFromSymbol(lexer.RightParenSymbol))
+
+	if !ok {
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.RightParenSymbol)) {
+		p.helpMessage(cursor, "Expected right paren after subquery")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	return slct, cursor, true
+}
+
+func (p *parser) parsePrimaryExpression(initialCursor uint) (*Expression, uint, bool) {
+	cursor := initialCursor
+
+	if p.expectToken(cursor+1, tokenFromKeyword(lexer.SelectKeyword)) ||
+		p.expectToken(cursor+1, tokenFromKeyword(lexer.WithKeyword)) {
+		if slct, newCursor, ok := p.parseSubquery(cursor); ok {
+			return &Expression{
+				Subquery: slct,
+				Kind:     SubqueryKind,
+			}, newCursor, true
+		}
+	}
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.ExistsKeyword)) {
+		op := p.tokens[cursor]
+		cursor++
+
+		slct, newCursor, ok := p.parseSubquery(cursor)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected subquery after EXISTS")
+
+			return nil, initialCursor, false
+		}
+
+		return &Expression{
+			Unary: &UnaryExpression{
+				Operand: Expression{
+					Subquery: slct,
+					Kind:     SubqueryKind,
+				},
+				Op: *op,
+			},
+			Kind: UnaryKind,
+		}, newCursor, true
+	}
+
+	if p.expectToken(cursor, tokenFromSymbol(lexer.LeftParenSymbol)) {
+		cursor++
+
+		exp, newCursor, ok := p.parseExpression(cursor, 0)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected expression after left paren")
+
+			return nil, initialCursor, false
+		}
+
+		cursor = newCursor
+
+		if !p.expectToken(cursor, tokenFromSymbol(lexer.RightParenSymbol)) {
+			p.helpMessage(cursor, "Expected right paren")
+
+			return nil, initialCursor, false
+		}
+
+		cursor++
+
+		return exp, cursor, true
+	}
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.NotKeyword)) {
+		op := p.tokens[cursor]
+		cursor++
+
+		operand, newCursor, ok := p.parseExpression(cursor, notBindingPower)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected expression after NOT")
+
+			return nil, initialCursor, false
+		}
+
+		return &Expression{
+			Unary: &UnaryExpression{
+				Operand: *operand,
+				Op:      *op,
+			},
+			Kind: UnaryKind,
+		}, newCursor, true
+	}
+
+	if exp, newCursor, ok := p.parseExtractExpression(cursor); ok {
+		return exp, newCursor, true
+	}
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.CaseKeyword)) {
+		return p.parseCaseExpression(cursor)
+	}
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.CastKeyword)) {
+		return p.parseCastExpression(cursor)
+	}
+
+	if exp, newCursor, ok := p.parseCallExpression(cursor); ok {
+		return exp, newCursor, true
+	}
+
+	return p.parseLiteralExpression(cursor)
+}
+
+// parseIsExpression parses what follows IS: [NOT] NULL, [NOT] TRUE/FALSE or
+// [NOT] DISTINCT FROM <expression>
+func (p *parser) parseIsExpression(initialCursor uint, left *Expression) (*Expression, uint, bool) {
+	cursor := initialCursor
+
+	op := p.tokens[cursor]
+	cursor++
+
+	not := p.expectToken(cursor, tokenFromKeyword(lexer.NotKeyword))
+
+	if not {
+		cursor++
+	}
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.DistinctKeyword)) {
+		op = p.tokens[cursor]
+		cursor++
+
+		if !p.expectToken(cursor, tokenFromKeyword(lexer.FromKeyword)) {
+			p.helpMessage(cursor, "Expected FROM after IS DISTINCT")
+
+			return nil, initialCursor, false
+		}
+
+		cursor++
+
+		right, newCursor, ok := p.parseExpression(cursor, isBindingPower+1)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected expression after IS DISTINCT FROM")
+
+			return nil, initialCursor, false
+		}
+
+		return &Expression{
+			Binary: &BinaryExpression{
+				A:   *left,
+				B:   *right,
+				Op:  *op,
+				Not: not,
+			},
+			Kind: BinaryKind,
+		}, newCursor, true
+	}
+
+	for _, kind := range []lexer.TokenKind{lexer.NullKind, lexer.BoolKind} {
+		if right, newCursor, ok := p.parseToken(cursor, kind); ok {
+			return &Expression{
+				Binary: &BinaryExpression{
+					A: *left,
+					B: Expression{
+						Literal: right,
+						Kind:    LiteralKind,
+					},
+					Op:  *op,
+					Not: not,
+				},
+				Kind: BinaryKind,
+			}, newCursor, true
+		}
+	}
+
+	p.helpMessage(cursor, "Expected NULL, TRUE, FALSE or DISTINCT FROM after IS")
+
+	return nil, initialCursor, false
+}
+
+// parseInExpression parses what follows IN, either a parenthesized
+// subquery or a parenthesized list of expressions
+func (p *parser) parseInExpression(initialCursor uint, left *Expression, not bool) (*Expression, uint, bool) {
+	cursor := initialCursor
+
+	op := p.tokens[cursor]
+	cursor++
+
+	in := BinaryExpression{
+		A:   *left,
+		Op:  *op,
+		Not: not,
+	}
+
+	if slct, newCursor, ok := p.parseSubquery(cursor); ok {
+		in.B = Expression{
+			Subquery: slct,
+			Kind:     SubqueryKind,
+		}
+
+		return &Expression{
+			Binary: &in,
+			Kind:   BinaryKind,
+		}, newCursor, true
+	}
+
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.LeftParenSymbol)) {
+		p.helpMessage(cursor, "Expected list or subquery after IN")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	exps, newCursor, ok := p.parseExpressions(cursor, []lexer.Token{tokenFromSymbol(lexer.RightParenSymbol)})
+
+	if !ok {
+		return nil, initialCursor, false
+	}
+
+	if len(*exps) == 0 {
+		p.helpMessage(cursor, "Expected expression in IN list")
+
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.RightParenSymbol)) {
+		p.helpMessage(cursor, "Expected right paren")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	in.B = Expression{
+		List: *exps,
+		Kind: ListKind,
+	}
+
+	return &Expression{
+		Binary: &in,
+		Kind:   BinaryKind,
+	}, cursor, true
+}
+
+// parseBetweenExpression parses what follows BETWEEN, low AND high
+func (p *parser) parseBetweenExpression(initialCursor uint, left *Expression, not bool) (*Expression, uint, bool) {
+	cursor := initialCursor + 1
+
+	// Bounds must not swallow the AND between them
+	low, newCursor, ok := p.parseExpression(cursor, predicateBindingPower+1)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected lower bound after BETWEEN")
+
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.AndKeyword)) {
+		p.helpMessage(cursor, "Expected AND")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	high, newCursor, ok := p.parseExpression(cursor, predicateBindingPower+1)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected upper bound after AND")
+
+		return nil, initialCursor, false
+	}
+
+	return &Expression{
+		Between: &BetweenExpression{
+			Operand: *left,
+			Low:     *low,
+			High:    *high,
+			Not:     not,
+		},
+		Kind: BetweenKind,
+	}, newCursor, true
+}
+
+// parseExpression parses operators by binding power, only consuming
+// operators that bind at least as tightly as minBp
+func (p *parser) parseExpression(initialCursor uint, minBp uint) (*Expression, uint, bool) {
+	cursor := initialCursor
+
+	exp, newCursor, ok := p.parsePrimaryExpression(cursor)
+
+	if !ok {
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	for cursor < uint(len(p.tokens)) {
+		opCursor := cursor
+
+		not := p.expectToken(cursor, tokenFromKeyword(lexer.NotKeyword))
+
+		if not {
+			opCursor++
+
+			if opCursor >= uint(len(p.tokens)) || !isNegatable(p.tokens[opCursor]) {
+				break
+			}
+		}
+
+		bp, ok := bindingPower(p.tokens[opCursor])
+
+		if !ok || bp < minBp {
+			break
+		}
+
+		if p.expectToken(opCursor, tokenFromKeyword(lexer.InKeyword)) {
+			exp, newCursor, ok = p.parseInExpression(opCursor, exp, not)
+
+			if !ok {
+				return nil, initialCursor, false
+			}
+
+			cursor = newCursor
+
+			continue
+		}
+
+		if p.expectToken(opCursor, tokenFromKeyword(lexer.BetweenKeyword)) {
+			exp, newCursor, ok = p.parseBetweenExpression(opCursor, exp, not)
+
+			if !ok {
+				return nil, initialCursor, false
+			}
+
+			cursor = newCursor
+
+			continue
+		}
+
+		if p.expectToken(cursor, tokenFromSymbol(lexer.CastSymbol)) {
+			op := p.tokens[cursor]
+			cursor++
+
+			dt, newCursor, ok := p.parseDatatype(cursor)
+
+			if !ok {
+				return nil, initialCursor, false
+			}
+
+			cursor = newCursor
+
+			exp = &Expression{
+				Cast: &CastExpression{
+					Operand: *exp,
+					Type:    *dt,
+					Op:      *op,
+				},
+				Kind: CastKind,
+			}
+
+			continue
+		}
+
+		if p.expectToken(cursor, tokenFromKeyword(lexer.IsKeyword)) {
+			exp, newCursor, ok = p.parseIsExpression(cursor, exp)
+
+			if !ok {
+				return nil, initialCursor, false
+			}
+
+			cursor = newCursor
+
+			continue
+		}
+
+		isLike := p.expectToken(opCursor, tokenFromKeyword(lexer.LikeKeyword)) ||
+			p.expectToken(opCursor, tokenFromKeyword(lexer.IlikeKeyword))
+
+		op := p.tokens[opCursor]
+		cursor = opCursor + 1
+
+		right, newCursor, ok := p.parseExpression(cursor, bp+1)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected right operand")
+
+			return nil, initialCursor, false
+		}
+
+		cursor = newCursor
+
+		binary := BinaryExpression{
+			A:   *exp,
+			B:   *right,
+			Op:  *op,
+			Not: not,
+		}
+
+		if isLike && p.expectToken(cursor, tokenFromKeyword(lexer.EscapeKeyword)) {
+			cursor++
+
+			escape, newCursor, ok := p.parseExpression(cursor, bp+1)
+
+			if !ok {
+				p.helpMessage(cursor, "Expected ESCAPE character")
+
+				return nil, initialCursor, false
+			}
+
+			binary.Escape = escape
+
+			cursor = newCursor
+		}
+
+		exp = &Expression{
+			Binary: &binary,
+			Kind:   BinaryKind,
+		}
+	}
+
+	return exp, cursor, true
+}
+
+func (p *parser) parseExpressions(initialCursor uint, delimiters []lexer.Token) (*[]*Expression, uint, bool) {
+	return p.parseList(initialCursor, delimiters, false)
+}
+
+// parseItems parses the items of a select list or the arguments of a call,
+// which can also be * or t.*
+func (p *parser) parseItems(initialCursor uint, delimiters []lexer.Token) (*[]*Expression, uint, bool) {
+	return p.parseList(initialCursor, delimiters, true)
+}
+
+// parseStar parses * or t.*
+func (p *parser) parseStar(initialCursor uint) (*Expression, uint, bool) {
+	cursor := initialCursor
+
+	star := Expression{Kind: LiteralKind}
+
+	if table, newCursor, ok := p.parseIdentifier(cursor); ok && p.expectToken(newCursor, tokenFromSymbol(lexer.DotSymbol)) {
+		star.Table = table
+		cursor = newCursor + 1
+	}
+
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.AsteriskSymbol)) {
+		return nil, initialCursor, false
+	}
+
+	star.Literal = p.tokens[cursor]
+	cursor++
+
+	return &star, cursor, true
+}
+
+func (p *parser) parseList(initialCursor uint, delimiters []lexer.Token, star bool) (*[]*Expression, uint, bool) {
+	cursor := initialCursor
+
+	exps := []*Expression{}
+
+outer:
+	// The end of the input ends the list too, as it does a statement whose
+	// semicolon has been split off
+	for cursor < uint(len(p.tokens)) {
+
+		current := p.tokens[cursor]
+
+		for _, delimiter := range delimiters {
+			if delimiter.Equals(current) {
+				break outer
+			}
+		}
+
+		if len(exps) > 0 {
+			if !p.expectToken(cursor, tokenFromSymbol(lexer.CommaSymbol)) {
+				p.helpMessage(cursor, "Expected comma")
+
+				return nil, initialCursor, false
+			}
+
+			cursor++
+		}
+
+		if star {
+			if exp, newCursor, ok := p.parseStar(cursor); ok {
+				cursor = newCursor
+				exps = append(exps, exp)
+
+				continue
+			}
+		}
+
+		exp, newCursor, ok := p.parseExpression(cursor, 0)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected expression")
+			return nil, initialCursor, false
+		}
+
+		cursor = newCursor
+
+		exps = append(exps, exp)
+	}
+
+	return &exps, cursor, true
+
+}
+
+func Parse(source string) (*Ast, error) {
+	a, diagnostics := ParseWithDiagnostics(source)
+
+	for _, d := range diagnostics {
+		if d.Severity == ErrorSeverity {
+			return nil, d
+		}
+	}
+
+	return a, nil
+}
+
+// ParseWithDiagnostics parses every statement it can. A statement that fails
+// is skipped up to the next semicolon and reported, so all the problems in
+// the source are found at once
+func ParseWithDiagnostics(source string) (*Ast, []Diagnostic) {
+	a := Ast{}
+
+	tokens, err := lexer.Lex(source)
+
+	if err != nil {
+		d := Diagnostic{
+			Severity: ErrorSeverity,
+			Message:  err.Error(),
+		}
+
+		var lexErr *lexer.Error
+
+		if errors.As(err, &lexErr) {
+			d.Start = lexErr.Loc
+			d.End = lexErr.Loc
+		}
+
+		return &a, []Diagnostic{d}
+	}
+
+	p := parser{tokens: tokens}
+
+	var diagnostics []Diagnostic
+
+	cursor := uint(0)
+
+	for cursor < uint(len(p.tokens)) {
+		if p.expectToken(cursor, tokenFromSymbol(lexer.SemicolonSymbol)) {
+			t := p.tokens[cursor]
+
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: WarningSeverity,
+				Start:    t.Loc,
+				End:      t.End(),
+				Message:  "Empty statement",
+			})
+
+			cursor++
+
+			continue
+		}
+
+		p.failed = false
+
+		statement, newCursor, ok := p.parseStatement(cursor)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected statement")
+			diagnostics = append(diagnostics, p.diagnostic())
+			cursor = p.skipStatement(cursor)
+
+			continue
+		}
+
+		cursor = newCursor
+
+		if cursor < uint(len(p.tokens)) && !p.expectToken(cursor, tokenFromSymbol(lexer.SemicolonSymbol)) {
+			p.helpMessage(cursor, "Expected semicolon delimiter between statements")
+			diagnostics = append(diagnostics, p.diagnostic())
+			cursor = p.skipStatement(cursor)
+
+			continue
+		}
+
+		a.Statements = append(a.Statements, statement)
+
+		cursor++
+	}
+
+	return &a, diagnostics
+}
+
+// StatementReader parses statements one at a time from a reader, so a large
+// dump is never lexed or held in memory whole. After a statement fails to
+// parse, Next carries on with the one following it
+type StatementReader struct {
+	lexer *lexer.Lexer
+}
+
+func NewStatementReader(r io.Reader) *StatementReader {
+	return &StatementReader{lexer: lexer.NewLexer(r)}
+}
+
+// Next returns the next statement, or io.EOF once the input is exhausted
+func (s *StatementReader) Next() (*Statement, error) {
+	var tokens []*lexer.Token
+	var splitter Splitter
+
+	for {
+		token, err := s.lexer.Next()
+
+		if err == io.EOF {
+			if len(tokens) == 0 {
+				return nil, io.EOF
+			}
+
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if splitter.Ends(token) {
+			if len(tokens) == 0 {
+				continue
+			}
+
+			break
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	p := parser{tokens: tokens}
+
+	statement, cursor, ok := p.parseStatement(0)
+
+	if !ok {
+		p.helpMessage(0, "Expected statement")
+
+		return nil, p.diagnostic()
+	}
+
+	if cursor < uint(len(tokens)) {
+		p.helpMessage(cursor, "Expected semicolon delimiter between statements")
+
+		return nil, p.diagnostic()
+	}
+
+	return statement, nil
+}
+
+func (p *parser) parseStatement(initialCursor uint) (*Statement, uint, bool) {
+	cursor := initialCursor
+
+	semicolonToken := tokenFromSymbol(lexer.SemicolonSymbol)
+
+	slct, newCursor, ok := p.parseSelectStatement(cursor, semicolonToken)
+
+	if ok {
+		return &Statement{
+			Kind:            SelectKind,
+			SelectStatement: slct,
+		}, newCursor, true
+	}
+
+	inst, newCursor, ok := p.parseInsertStatement(cursor, semicolonToken)
+
+	if ok {
+		return &Statement{
+			Kind:            InsertKind,
+			InsertStatement: inst,
+		}, newCursor, true
+	}
+
+	createTbl, newCursor, ok := p.parseCreateTableStatement(cursor, semicolonToken)
+
+	if ok {
+		return &Statement{
+			Kind:                 CreateTableKind,
+			CreateTableStatement: createTbl,
+		}, newCursor, true
+	}
+
+	alterTbl, newCursor, ok := p.parseAlterTableStatement(cursor, semicolonToken)
+
+	if ok {
+		return &Statement{
+			Kind:                AlterTableKind,
+			AlterTableStatement: alterTbl,
+		}, newCursor, true
+	}
+
+	createSchema, newCursor, ok := p.parseCreateSchemaStatement(cursor)
+
+	if ok {
+		return &Statement{
+			Kind:                  CreateSchemaKind,
+			CreateSchemaStatement: createSchema,
+		}, newCursor, true
+	}
+
+	dropSchema, newCursor, ok := p.parseDropSchemaStatement(cursor)
+
+	if ok {
+		return &Statement{
+			Kind:                DropSchemaKind,
+			DropSchemaStatement: dropSchema,
+		}, newCursor, true
+	}
+
+	attach, newCursor, ok := p.parseAttachStatement(cursor)
+
+	if ok {
+		return &Statement{
+			Kind:            AttachKind,
+			AttachStatement: attach,
+		}, newCursor, true
+	}
+
+	createView, newCursor, ok := p.parseCreateViewStatement(cursor, semicolonToken)
+
+	if ok {
+		return &Statement{
+			Kind:                CreateViewKind,
+			CreateViewStatement: createView,
+		}, newCursor, true
+	}
+
+	refreshView, newCursor, ok := p.parseRefreshViewStatement(cursor)
+
+	if ok {
+		return &Statement{
+			Kind:                 RefreshViewKind,
+			RefreshViewStatement: refreshView,
+		}, newCursor, true
+	}
+
+	dropView, newCursor, ok := p.parseDropViewStatement(cursor)
+
+	if ok {
+		return &Statement{
+			Kind:              DropViewKind,
+			DropViewStatement: dropView,
+		}, newCursor, true
+	}
+
+	trigger, newCursor, ok := p.parseCreateTriggerStatement(cursor)
+
+	if ok {
+		return &Statement{
+			Kind:                   CreateTriggerKind,
+			CreateTriggerStatement: trigger,
+		}, newCursor, true
+	}
+
+	return nil, initialCursor, false
+
+}
+
+func (p *parser) parseIdentifiers(initialCursor uint) ([]*lexer.Token, uint, bool) {
+	cursor := initialCursor
+
+	var ids []*lexer.Token
+
+	for {
+		if len(ids) > 0 {
+			if !p.expectToken(cursor, tokenFromSymbol(lexer.CommaSymbol)) {
+				break
+			}
+
+			cursor++
+		}
+
+		id, newCursor, ok := p.parseIdentifier(cursor)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected identifier")
+
+			return nil, initialCursor, false
+		}
+
+		cursor = newCursor
+
+		ids = append(ids, id)
+	}
+
+	return ids, cursor, true
+}
+
+// parseWithClause parses WITH [RECURSIVE] name [(cols)] AS (SELECT ...), ...
+func (p *parser) parseWithClause(initialCursor uint) (*WithClause, uint, bool) {
+	cursor := initialCursor
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.WithKeyword)) {
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	with := WithClause{}
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.RecursiveKeyword)) {
+		with.Recursive = true
+
+		cursor++
+	}
+
+	for {
+		if len(with.Ctes) > 0 {
+			if !p.expectToken(cursor, tokenFromSymbol(lexer.CommaSymbol)) {
+				break
+			}
+
+			cursor++
+		}
+
+		name, newCursor, ok := p.parseIdentifier(cursor)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected common table expression name")
+
+			return nil, initialCursor, false
+		}
+
+		cursor = newCursor
+
+		cte := CommonTableExpression{Name: *name}
+
+		if p.expectToken(cursor, tokenFromSymbol(lexer.LeftParenSymbol)) {
+			cursor++
+
+			cols, newCursor, ok := p.parseIdentifiers(cursor)
+
+			if !ok {
+				return nil, initialCursor, false
+			}
+
+			cursor = newCursor
+
+			if !p.expectToken(cursor, tokenFromSymbol(lexer.RightParenSymbol)) {
+				p.helpMessage(cursor, "Expected right paren")
+
+				return nil, initialCursor, false
+			}
+
+			cursor++
+
+			cte.Columns = cols
+		}
+
+		if !p.expectToken(cursor, tokenFromKeyword(lexer.AsKeyord)) {
+			p.helpMessage(cursor, "Expected AS")
+
+			return nil, initialCursor, false
+		}
+
+		cursor++
+
+		query, newCursor, ok := p.parseSubquery(cursor)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected subquery")
+
+			return nil, initialCursor, false
+		}
+
+		cursor = newCursor
+
+		cte.Query = query
+
+		with.Ctes = append(with.Ctes, &cte)
+	}
+
+	return &with, cursor, true
+}
+
+func (p *parser) parseSelectStatement(initialCursor uint, delimiter lexer.Token) (*SelectStatement, uint, bool) {
+	cursor := initialCursor
+
+	with, newCursor, ok := p.parseWithClause(cursor)
+
+	if ok {
+		cursor = newCursor
+	}
+
+	slct, newCursor, ok := p.parseCompoundSelect(cursor, delimiter, 0)
+
+	if !ok {
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	if with != nil {
+		if slct.With != nil {
+			p.helpMessage(initialCursor, "Multiple WITH clauses not allowed")
+
+			return nil, initialCursor, false
+		}
+
+		slct.With = with
+	}
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.OrderKeyword)) {
+		if slct.OrderBy != nil {
+			p.helpMessage(cursor, "Multiple ORDER BY clauses not allowed")
+
+			return nil, initialCursor, false
+		}
+
+		orderBy, newCursor, ok := p.parseOrderBy(cursor)
+
+		if !ok {
+			return nil, initialCursor, false
+		}
+
+		slct.OrderBy = orderBy
+
+		cursor = newCursor
+	}
+
+	clauses := []struct {
+		keyword lexer.Keyword
+		exp     **Expression
+	}{
+		{lexer.LimitKeyword, &slct.Limit},
+		{lexer.OffsetKeyword, &slct.Offset},
+	}
+
+	for _, clause := range clauses {
+		if !p.expectToken(cursor, tokenFromKeyword(clause.keyword)) {
+			continue
+		}
+
+		if *clause.exp != nil {
+			p.helpMessage(cursor, fmt.Sprintf("Multiple %s clauses not allowed", clause.keyword))
+
+			return nil, initialCursor, false
+		}
+
+		cursor++
+
+		exp, newCursor, ok := p.parseExpression(cursor, 0)
+
+		if !ok {
+			p.helpMessage(cursor, fmt.Sprintf("Expected %s expression", clause.keyword))
+
+			return nil, initialCursor, false
+		}
+
+		*clause.exp = exp
+
+		cursor = newCursor
+	}
+
+	return slct, cursor, true
+}
+
+// parseOrderBy parses ORDER BY <expression> [ASC | DESC], ...
+func (p *parser) parseOrderBy(initialCursor uint) ([]*OrderByItem, uint, bool) {
+	cursor := initialCursor
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.OrderKeyword)) {
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.ByKeyword)) {
+		p.helpMessage(cursor, "Expected BY")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	var items []*OrderByItem
+
+	for {
+		if len(items) > 0 {
+			if !p.expectToken(cursor, tokenFromSymbol(lexer.CommaSymbol)) {
+				break
+			}
+
+			cursor++
+		}
+
+		exp, newCursor, ok := p.parseExpression(cursor, 0)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected ORDER BY expression")
+
+			return nil, initialCursor, false
+		}
+
+		cursor = newCursor
+
+		item := OrderByItem{Exp: exp}
+
+		if p.expectToken(cursor, tokenFromKeyword(lexer.DescKeyword)) {
+			item.Desc = true
+			cursor++
+		} else if p.expectToken(cursor, tokenFromKeyword(lexer.AscKeyword)) {
+			cursor++
+		}
+
+		items = append(items, &item)
+	}
+
+	return items, cursor, true
+}
+
+// setOperators lists the set operators by binding power, INTERSECT binds
+// tighter than UNION and EXCEPT
+var setOperators = []binaryOperator{
+	{tokenFromKeyword(lexer.UnionKeyword), 1},
+	{tokenFromKeyword(lexer.ExceptKeyword), 1},
+	{tokenFromKeyword(lexer.IntersectKeyword), 2},
+}
+
+// BindingPower is how tightly the set operator at the top of the query
+// binds, which decides where it needs parens when printed. Queries that
+// aren't compound bind tightest
+func (s *SelectStatement) BindingPower() uint {
+	if s.SetOperation != nil {
+		for _, op := range setOperators {
+			if op.Token.Equals(&s.SetOperation.Op) {
+				return op.BindingPower
+			}
+		}
+	}
+
+	return setOperators[len(setOperators)-1].BindingPower + 1
+}
+
+// columnCount is how many columns the query returns, or -1 when a * leaves
+// it to the tables
+func columnCount(slct *SelectStatement) int {
+	if slct.SetOperation != nil {
+		return columnCount(slct.SetOperation.Left)
+	}
+
+	for _, item := range slct.Item {
+		if item.Star() {
+			return -1
+		}
+	}
+
+	return len(slct.Item)
+}
+
+// parseCompoundSelect parses queries combined with set operators, only
+// consuming operators that bind at least as tightly as minBp
+func (p *parser) parseCompoundSelect(initialCursor uint, delimiter lexer.Token, minBp uint) (*SelectStatement, uint, bool) {
+	cursor := initialCursor
+
+	var left *SelectStatement
+	var newCursor uint
+	var ok bool
+
+	if p.expectToken(cursor, tokenFromSymbol(lexer.LeftParenSymbol)) {
+		left, newCursor, ok = p.parseSubquery(cursor)
+	} else {
+		left, newCursor, ok = p.parseSelectCore(cursor, delimiter)
+	}
+
+	if !ok {
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+outer:
+	for cursor < uint(len(p.tokens)) {
+		for _, op := range setOperators {
+			if !op.Token.Equals(p.tokens[cursor]) {
+				continue
+			}
+
+			if op.BindingPower < minBp {
+				break outer
+			}
+
+			operation := SetOperation{
+				Op:   *p.tokens[cursor],
+				Left: left,
+			}
+
+			cursor++
+
+			if p.expectToken(cursor, tokenFromKeyword(lexer.AllKeyword)) {
+				operation.All = true
+				cursor++
+			} else if p.expectToken(cursor, tokenFromKeyword(lexer.DistinctKeyword)) {
+				cursor++
+			}
+
+			right, newCursor, ok := p.parseCompoundSelect(cursor, delimiter, op.BindingPower+1)
+
+			if !ok {
+				p.helpMessage(cursor, fmt.Sprintf("Expected query after %s", operation.Op.Value))
+
+				return nil, initialCursor, false
+			}
+
+			if l, r := columnCount(left), columnCount(right); l >= 0 && r >= 0 && l != r {
+				p.helpMessage(cursor, fmt.Sprintf("Each %s query must have the same number of columns", operation.Op.Value))
+
+				return nil, initialCursor, false
+			}
+
+			operation.Right = right
+
+			left = &SelectStatement{SetOperation: &operation}
+
+			cursor = newCursor
+
+			continue outer
+		}
+
+		break
+	}
+
+	return left, cursor, true
+}
+
+// parseSelectCore parses a single SELECT without WITH, ORDER BY or LIMIT
+func (p *parser) parseSelectCore(initialCursor uint, delimiter lexer.Token) (*SelectStatement, uint, bool) {
+	cursor := initialCursor
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.SelectKeyword)) {
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	slct := SelectStatement{}
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.DistinctKeyword)) {
+		slct.Distinct = true
+		cursor++
+
+		if p.expectToken(cursor, tokenFromKeyword(lexer.OnKeyword)) {
+			cursor++
+
+			if !p.expectToken(cursor, tokenFromSymbol(lexer.LeftParenSymbol)) {
+				p.helpMessage(cursor, "Expected left paren after DISTINCT ON")
+
+				return nil, initialCursor, false
+			}
+
+			cursor++
+
+			exps, newCursor, ok := p.parseExpressions(cursor, []lexer.Token{tokenFromSymbol(lexer.RightParenSymbol)})
+
+			if !ok {
+				return nil, initialCursor, false
+			}
+
+			if len(*exps) == 0 {
+				p.helpMessage(cursor, "Expected DISTINCT ON expression")
+
+				return nil, initialCursor, false
+			}
+
+			cursor = newCursor
+
+			if !p.expectToken(cursor, tokenFromSymbol(lexer.RightParenSymbol)) {
+				p.helpMessage(cursor, "Expected right paren")
+
+				return nil, initialCursor, false
+			}
+
+			cursor++
+
+			slct.DistinctOn = *exps
+		}
+	} else if p.expectToken(cursor, tokenFromKeyword(lexer.AllKeyword)) {
+		cursor++
+	}
+
+	exps, newCursor, ok := p.parseItems(cursor, []lexer.Token{
+		delimiter,
+		tokenFromKeyword(lexer.FromKeyword),
+		tokenFromKeyword(lexer.WhereKeyword),
+		tokenFromKeyword(lexer.UnionKeyword),
+		tokenFromKeyword(lexer.IntersectKeyword),
+		tokenFromKeyword(lexer.ExceptKeyword),
+		tokenFromKeyword(lexer.OrderKeyword),
+		tokenFromKeyword(lexer.LimitKeyword),
+		tokenFromKeyword(lexer.OffsetKeyword),
+	})
+
+	if !ok {
+		return nil, initialCursor, false
+	}
+
+	if len(*exps) == 0 {
+		p.helpMessage(cursor, "Expected SELECT expression")
+
+		return nil, initialCursor, false
+	}
+
+	slct.Item = *exps
+	cursor = newCursor
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.FromKeyword)) {
+		cursor++
+
+		from, newCursor, ok := p.parseFromItem(cursor)
+
+		if !ok {
+			return nil, initialCursor, false
+		}
+
+		slct.From = from
+
+		cursor = newCursor
+	}
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.WhereKeyword)) {
+		cursor++
+
+		where, newCursor, ok := p.parseExpression(cursor, 0)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected WHERE conditionals")
+
+			return nil, initialCursor, false
+		}
+
+		slct.Where = where
+
+		cursor = newCursor
+	}
+
+	return &slct, cursor, true
+}
+
+// parseTableName parses a table name, which may be qualified by its schema
+// as in s.t
+func (p *parser) parseTableName(initialCursor uint) (*lexer.Token, *lexer.Token, uint, bool) {
+	cursor := initialCursor
+
+	name, newCursor, ok := p.parseIdentifier(cursor)
+
+	if !ok {
+		return nil, nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.DotSymbol)) {
+		return nil, name, cursor, true
+	}
+
+	cursor++
+
+	table, newCursor, ok := p.parseIdentifier(cursor)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected table name")
+
+		return nil, nil, initialCursor, false
+	}
+
+	return name, table, newCursor, true
+}
+
+func (p *parser) parseFromItem(initialCursor uint) (*FromItem, uint, bool) {
+	cursor := initialCursor
+
+	var item FromItem
+
+	if schema, table, newCursor, ok := p.parseTableName(cursor); ok {
+		item.Schema = schema
+		item.Table = table
+		item.Kind = TableFromKind
+
+		cursor = newCursor
+	} else if slct, newCursor, ok := p.parseSubquery(cursor); ok {
+		item.Subquery = slct
+		item.Kind = SubqueryFromKind
+
+		cursor = newCursor
+	} else {
+		p.helpMessage(cursor, "Expected table name or subquery after FROM")
+
+		return nil, initialCursor, false
+	}
+
+	// AS is optional, as in FROM users [AS] u
+	hasAs := p.expectToken(cursor, tokenFromKeyword(lexer.AsKeyord))
+
+	if hasAs {
+		cursor++
+	}
+
+	alias, newCursor, ok := p.parseIdentifier(cursor)
+
+	if ok {
+		item.Alias = alias
+
+		cursor = newCursor
+	} else if hasAs {
+		p.helpMessage(cursor, "Expected alias after AS")
+
+		return nil, initialCursor, false
+	}
+
+	if item.Kind == SubqueryFromKind && item.Alias == nil {
+		p.helpMessage(cursor, "Expected alias for subquery in FROM")
+
+		return nil, initialCursor, false
+	}
+
+	return &item, cursor, true
+}
+
+func (p *parser) parseInsertStatement(initialCursor uint, delimiter lexer.Token) (*InsertStatement, uint, bool) {
+	cursor := initialCursor
+
+	with, newCursor, ok := p.parseWithClause(cursor)
+
+	if ok {
+		cursor = newCursor
+	}
+
+	// Look for INSERT
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.InsertKeyword)) {
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	// Look for INTO
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.IntoKeyword)) {
+		p.helpMessage(cursor, "Expected INTO")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	schema, table, newCursor, ok := p.parseTableName(cursor)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected table name")
+
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	// Look for VALUES
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.ValuesKeyword)) {
+		p.helpMessage(cursor, "Expected VALUES")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.LeftParenSymbol)) {
+		p.helpMessage(cursor, "Expected left paren")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	values, newCursor, ok := p.parseExpressions(cursor, []lexer.Token{tokenFromSymbol(lexer.RightParenSymbol)})
+
+	if !ok {
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.RightParenSymbol)) {
+		p.helpMessage(cursor, "Expected right paren")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	return &InsertStatement{
+		With:   with,
+		Schema: schema,
+		Table:  *table,
+		Values: values,
+	}, cursor, true
+}
+
+func (p *parser) parseCreateTableStatement(initialCursor uint, delimiter lexer.Token) (*CreateTableStatement, uint, bool) {
+	cursor := initialCursor
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.CreateKeyword)) {
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.TableKeyword)) {
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	schema, name, newCursor, ok := p.parseTableName(cursor)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected table name")
+
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.LeftParenSymbol)) {
+		p.helpMessage(cursor, "Expected left paren")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	cols, newCursor, ok := p.parseColumnDefinitions(cursor, tokenFromSymbol(lexer.RightParenSymbol))
+
+	if !ok {
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.RightParenSymbol)) {
+		p.helpMessage(cursor, "Expected right paren")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	return &CreateTableStatement{
+		Schema: schema,
+		Name:   *name,
+		Cols:   cols,
+	}, cursor, true
+}
+
+func (p *parser) parseAlterTableStatement(initialCursor uint, delimiter lexer.Token) (*AlterTableStatement, uint, bool) {
+	cursor := initialCursor
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.AlterKeyword)) {
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.TableKeyword)) {
+		p.helpMessage(cursor, "Expected TABLE")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	schema, name, newCursor, ok := p.parseTableName(cursor)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected table name")
+
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	alter := AlterTableStatement{Schema: schema, Name: *name}
+
+	switch {
+	case p.expectToken(cursor, tokenFromKeyword(lexer.AddKeyword)):
+		cursor++
+
+		// COLUMN is optional, as in ADD [COLUMN]
+		if p.expectToken(cursor, tokenFromKeyword(lexer.ColumnKeyword)) {
+			cursor++
+		}
+
+		cd, newCursor, ok := p.parseColumnDefinition(cursor)
+
+		if !ok {
+			return nil, initialCursor, false
+		}
+
+		alter.Kind = AddColumnKind
+		alter.AddColumn = cd
+
+		cursor = newCursor
+
+	case p.expectToken(cursor, tokenFromKeyword(lexer.DropKeyword)):
+		cursor++
+
+		if p.expectToken(cursor, tokenFromKeyword(lexer.ColumnKeyword)) {
+			cursor++
+		}
+
+		column, newCursor, ok := p.parseIdentifier(cursor)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected column name")
+
+			return nil, initialCursor, false
+		}
+
+		alter.Kind = DropColumnKind
+		alter.Column = *column
+
+		cursor = newCursor
+
+	case p.expectToken(cursor, tokenFromKeyword(lexer.RenameKeyword)):
+		cursor++
+
+		alter.Kind = RenameTableKind
+
+		if !p.expectToken(cursor, tokenFromKeyword(lexer.ToKeyword)) {
+			if p.expectToken(cursor, tokenFromKeyword(lexer.ColumnKeyword)) {
+				cursor++
+			}
+
+			column, newCursor, ok := p.parseIdentifier(cursor)
+
+			if !ok {
+				p.helpMessage(cursor, "Expected column name or TO")
+
+				return nil, initialCursor, false
+			}
+
+			alter.Kind = RenameColumnKind
+			alter.Column = *column
+
+			cursor = newCursor
+
+			if !p.expectToken(cursor, tokenFromKeyword(lexer.ToKeyword)) {
+				p.helpMessage(cursor, "Expected TO")
+
+				return nil, initialCursor, false
+			}
+		}
+
+		cursor++
+
+		newName, newCursor, ok := p.parseIdentifier(cursor)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected new name")
+
+			return nil, initialCursor, false
+		}
+
+		alter.NewName = *newName
+
+		cursor = newCursor
+
+	default:
+		p.helpMessage(cursor, "Expected ADD, DROP or RENAME")
+
+		return nil, initialCursor, false
+	}
+
+	return &alter, cursor, true
+}
+
+func (p *parser) parseCreateSchemaStatement(initialCursor uint) (*CreateSchemaStatement, uint, bool) {
+	cursor := initialCursor
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.CreateKeyword)) {
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.SchemaKeyword)) {
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	name, newCursor, ok := p.parseIdentifier(cursor)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected schema name")
+
+		return nil, initialCursor, false
+	}
+
+	return &CreateSchemaStatement{Name: *name}, newCursor, true
+}
+
+func (p *parser) parseDropSchemaStatement(initialCursor uint) (*DropSchemaStatement, uint, bool) {
+	cursor := initialCursor
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.DropKeyword)) {
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.SchemaKeyword)) {
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	name, newCursor, ok := p.parseIdentifier(cursor)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected schema name")
+
+		return nil, initialCursor, false
+	}
+
+	return &DropSchemaStatement{Name: *name}, newCursor, true
+}
+
+// parseCreateViewStatement parses
+// CREATE [MATERIALIZED] VIEW name [(columns)] AS query
+func (p *parser) parseCreateViewStatement(initialCursor uint, delimiter lexer.Token) (*CreateViewStatement, uint, bool) {
+	cursor := initialCursor
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.CreateKeyword)) {
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	view := CreateViewStatement{}
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.MaterializedKeyword)) {
+		view.Materialized = true
+		cursor++
+	}
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.ViewKeyword)) {
+		if view.Materialized {
+			p.helpMessage(cursor, "Expected VIEW")
+		} else {
+			p.helpMessage(cursor, "Expected TABLE, SCHEMA, VIEW or TRIGGER")
+		}
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	schema, name, newCursor, ok := p.parseTableName(cursor)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected view name")
+
+		return nil, initialCursor, false
+	}
+
+	view.Schema = schema
+	view.Name = *name
+	cursor = newCursor
+
+	if p.expectToken(cursor, tokenFromSymbol(lexer.LeftParenSymbol)) {
+		cursor++
+
+		cols, newCursor, ok := p.parseIdentifiers(cursor)
+
+		if !ok {
+			return nil, initialCursor, false
+		}
+
+		cursor = newCursor
+
+		if !p.expectToken(cursor, tokenFromSymbol(lexer.RightParenSymbol)) {
+			p.helpMessage(cursor, "Expected right paren")
+
+			return nil, initialCursor, false
+		}
+
+		cursor++
+
+		view.Columns = cols
+	}
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.AsKeyord)) {
+		p.helpMessage(cursor, "Expected AS")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	query, newCursor, ok := p.parseSelectStatement(cursor, delimiter)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected query")
+
+		return nil, initialCursor, false
+	}
+
+	view.Query = query
+
+	return &view, newCursor, true
+}
+
+// parseRefreshViewStatement parses REFRESH MATERIALIZED VIEW name
+func (p *parser) parseRefreshViewStatement(initialCursor uint) (*RefreshViewStatement, uint, bool) {
+	cursor := initialCursor
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.RefreshKeyword)) {
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	for _, kw := range []lexer.Keyword{lexer.MaterializedKeyword, lexer.ViewKeyword} {
+		if !p.expectToken(cursor, tokenFromKeyword(kw)) {
+			p.helpMessage(cursor, "Expected MATERIALIZED VIEW")
+
+			return nil, initialCursor, false
+		}
+
+		cursor++
+	}
+
+	schema, name, newCursor, ok := p.parseTableName(cursor)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected view name")
+
+		return nil, initialCursor, false
+	}
+
+	return &RefreshViewStatement{Schema: schema, Name: *name}, newCursor, true
+}
+
+// parseDropViewStatement parses DROP [MATERIALIZED] VIEW name
+func (p *parser) parseDropViewStatement(initialCursor uint) (*DropViewStatement, uint, bool) {
+	cursor := initialCursor
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.DropKeyword)) {
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	drop := DropViewStatement{}
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.MaterializedKeyword)) {
+		drop.Materialized = true
+		cursor++
+	}
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.ViewKeyword)) {
+		if drop.Materialized {
+			p.helpMessage(cursor, "Expected VIEW")
+		} else {
+			p.helpMessage(cursor, "Expected SCHEMA or VIEW")
+		}
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	schema, name, newCursor, ok := p.parseTableName(cursor)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected view name")
+
+		return nil, initialCursor, false
+	}
+
+	drop.Schema = schema
+	drop.Name = *name
+
+	return &drop, newCursor, true
+}
+
+// expectKeywords finds the first of kws at cursor
+func (p *parser) expectKeywords(cursor uint, kws ...lexer.Keyword) (*lexer.Token, bool) {
+	for _, kw := range kws {
+		if p.expectToken(cursor, tokenFromKeyword(kw)) {
+			return p.tokens[cursor], true
+		}
+	}
+
+	return nil, false
+}
+
+// parseCreateTriggerStatement parses
+// CREATE TRIGGER name BEFORE|AFTER INSERT|UPDATE|DELETE ON table
+// FOR EACH ROW [WHEN condition] BEGIN statement; ... END
+func (p *parser) parseCreateTriggerStatement(initialCursor uint) (*CreateTriggerStatement, uint, bool) {
+	cursor := initialCursor
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.CreateKeyword)) ||
+		!p.expectToken(cursor+1, tokenFromKeyword(lexer.TriggerKeyword)) {
+		return nil, initialCursor, false
+	}
+
+	cursor += 2
+
+	name, newCursor, ok := p.parseIdentifier(cursor)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected trigger name")
+
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	trigger := CreateTriggerStatement{Name: *name}
+
+	timing, ok := p.expectKeywords(cursor, lexer.BeforeKeyword, lexer.AfterKeyword)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected BEFORE or AFTER")
+
+		return nil, initialCursor, false
+	}
+
+	trigger.Timing = *timing
+	cursor++
+
+	event, ok := p.expectKeywords(cursor, lexer.InsertKeyword, lexer.UpdateKeyword, lexer.DeleteKeyword)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected INSERT, UPDATE or DELETE")
+
+		return nil, initialCursor, false
+	}
+
+	trigger.Event = *event
+	cursor++
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.OnKeyword)) {
+		p.helpMessage(cursor, "Expected ON")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	schema, table, newCursor, ok := p.parseTableName(cursor)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected table name")
+
+		return nil, initialCursor, false
+	}
+
+	trigger.Schema = schema
+	trigger.Table = *table
+	cursor = newCursor
+
+	for _, kw := range []lexer.Keyword{lexer.ForKeyword, lexer.EachKeyword, lexer.RowKeyword} {
+		if !p.expectToken(cursor, tokenFromKeyword(kw)) {
+			p.helpMessage(cursor, "Expected FOR EACH ROW")
+
+			return nil, initialCursor, false
+		}
+
+		cursor++
+	}
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.WhenKeyword)) {
+		cursor++
+
+		when, newCursor, ok := p.parseExpression(cursor, 0)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected WHEN condition")
+
+			return nil, initialCursor, false
+		}
+
+		trigger.When = when
+		cursor = newCursor
+	}
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.BeginKeyword)) {
+		p.helpMessage(cursor, "Expected BEGIN")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	semicolon := tokenFromSymbol(lexer.SemicolonSymbol)
+
+	for len(trigger.Body) == 0 || !p.expectToken(cursor, tokenFromKeyword(lexer.EndKeyword)) {
+		var stmt *Statement
+
+		if inst, newCursor, ok := p.parseInsertStatement(cursor, semicolon); ok {
+			stmt = &Statement{Kind: InsertKind, InsertStatement: inst}
+			cursor = newCursor
+		} else if slct, newCursor, ok := p.parseSelectStatement(cursor, semicolon); ok {
+			stmt = &Statement{Kind: SelectKind, SelectStatement: slct}
+			cursor = newCursor
+		} else {
+			p.helpMessage(cursor, "Expected INSERT or SELECT in trigger body")
+
+			return nil, initialCursor, false
+		}
+
+		if !p.expectToken(cursor, semicolon) {
+			p.helpMessage(cursor, "Expected semicolon after statement in trigger body")
+
+			return nil, initialCursor, false
+		}
+
+		cursor++
+
+		trigger.Body = append(trigger.Body, stmt)
+	}
+
+	cursor++
+
+	return &trigger, cursor, true
+}
+
+// parseAttachStatement parses ATTACH [DATABASE] 'file' AS name
+func (p *parser) parseAttachStatement(initialCursor uint) (*AttachStatement, uint, bool) {
+	cursor := initialCursor
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.AttachKeyword)) {
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.DatabaseKeyword)) {
+		cursor++
+	}
+
+	file, newCursor, ok := p.parseToken(cursor, lexer.StringKind)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected database file name")
+
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	if !p.expectToken(cursor, tokenFromKeyword(lexer.AsKeyord)) {
+		p.helpMessage(cursor, "Expected AS")
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	name, newCursor, ok := p.parseIdentifier(cursor)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected schema name")
+
+		return nil, initialCursor, false
+	}
+
+	return &AttachStatement{File: *file, Name: *name}, newCursor, true
+}
+
+func (p *parser) parseColumnDefinitions(initialCursor uint, delimiter lexer.Token) (*[]*ColumnDefinition, uint, bool) {
+	cursor := initialCursor
+
+	cds := []*ColumnDefinition{}
+
+	for {
+		if cursor >= uint(len(p.tokens)) {
+			return nil, initialCursor, false
+		}
+
+		if delimiter.Equals(p.tokens[cursor]) {
+			break
+		}
+
+		if len(cds) > 0 {
+			if !p.expectToken(cursor, tokenFromSymbol(lexer.CommaSymbol)) {
+				p.helpMessage(cursor, "Expected comma")
+
+				return nil, initialCursor, false
+			}
+
+			cursor++
+		}
+
+		cd, newCursor, ok := p.parseColumnDefinition(cursor)
+
+		if !ok {
+			return nil, initialCursor, false
+		}
+
+		cursor = newCursor
+
+		cds = append(cds, cd)
+	}
+
+	return &cds, cursor, true
+}
+
+func (p *parser) parseColumnDefinition(initialCursor uint) (*ColumnDefinition, uint, bool) {
+	cursor := initialCursor
+
+	id, newCursor, ok := p.parseIdentifier(cursor)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected column name")
+
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	datatype, newCursor, ok := p.parseDatatype(cursor)
+
+	if !ok {
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	cd := ColumnDefinition{
+		Name:     *id,
+		Datatype: *datatype,
+	}
+
+	if p.expectToken(cursor, tokenFromKeyword(lexer.DefaultKeyword)) {
+		cursor++
+
+		def, newCursor, ok := p.parseExpression(cursor, 0)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected DEFAULT expression")
+
+			return nil, initialCursor, false
+		}
+
+		cd.Default = def
+
+		cursor = newCursor
+	}
+
+	return &cd, cursor, true
+}
+
+// datatypes maps every supported column type to the maximum number of
+// modifiers it accepts between parens
+var datatypes = map[lexer.Keyword]int{
+	lexer.IntKeyword:       0,
+	lexer.BigintKeyword:    0,
+	lexer.RealKeyword:      0,
+	lexer.DoubleKeyword:    0,
+	lexer.NumericKeyword:   2,
+	lexer.TextKeyword:      0,
+	lexer.VarcharKeyword:   1,
+	lexer.BooleanKeyword:   0,
+	lexer.DateKeyword:      0,
+	lexer.TimestampKeyword: 0,
+	lexer.BlobKeyword:      0,
+}
+
+func (p *parser) parseDatatype(initialCursor uint) (*DataType, uint, bool) {
+	cursor := initialCursor
+
+	name, newCursor, ok := p.parseToken(cursor, lexer.KeywordKind)
+
+	if !ok {
+		p.helpMessage(cursor, "Expected type")
+
+		return nil, initialCursor, false
+	}
+
+	maxParams, ok := datatypes[lexer.Keyword(name.Value)]
+
+	if !ok {
+		p.helpMessage(cursor, "Expected type")
+
+		return nil, initialCursor, false
+	}
+
+	cursor = newCursor
+
+	dt := DataType{Name: *name}
+
+	if !p.expectToken(cursor, tokenFromSymbol(lexer.LeftParenSymbol)) {
+		return &dt, cursor, true
+	}
+
+	if maxParams == 0 {
+		p.helpMessage(cursor, fmt.Sprintf("Type %s does not accept modifiers", name.Value))
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	var values []uint64
+	var cursors []uint
+
+	for !p.expectToken(cursor, tokenFromSymbol(lexer.RightParenSymbol)) {
+		if len(dt.Params) > 0 {
+			if !p.expectToken(cursor, tokenFromSymbol(lexer.CommaSymbol)) {
+				p.helpMessage(cursor, "Expected comma")
+
+				return nil, initialCursor, false
+			}
+
+			cursor++
+		}
+
+		param, newCursor, ok := p.parseToken(cursor, lexer.NumericKind)
+
+		if !ok {
+			p.helpMessage(cursor, "Expected numeric type modifier")
+
+			return nil, initialCursor, false
+		}
+
+		// Lengths, precisions and scales are counts of characters or digits
+		value, err := strconv.ParseUint(param.Value, 10, 32)
+
+		if err != nil {
+			p.helpMessage(cursor, "Expected integer type modifier")
+
+			return nil, initialCursor, false
+		}
+
+		values = append(values, value)
+		cursors = append(cursors, cursor)
+		cursor = newCursor
+
+		dt.Params = append(dt.Params, param)
+	}
+
+	if len(dt.Params) == 0 || len(dt.Params) > maxParams {
+		p.helpMessage(cursor, fmt.Sprintf("Type %s accepts at most %d modifiers", name.Value, maxParams))
+
+		return nil, initialCursor, false
+	}
+
+	// The second modifier of NUMERIC(p, s) is its scale
+	if len(values) == 2 && values[1] > values[0] {
+		p.helpMessage(cursors[1], fmt.Sprintf("Scale %d of %s can't exceed its precision %d", values[1], name.Value, values[0]))
+
+		return nil, initialCursor, false
+	}
+
+	cursor++
+
+	return &dt, cursor, true
 }
@@ -0,0 +1,1686 @@
+package parser
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/Jadiscke/myown-sql/internal/lexer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_createTable(t *testing.T) {
+	tests := []struct {
+		ok     bool
+		input  string
+		types  []string
+		params [][]string
+	}{
+		{
+			ok:     true,
+			input:  "CREATE TABLE u (id INT, name TEXT);",
+			types:  []string{"int", "text"},
+			params: [][]string{nil, nil},
+		},
+		{
+			ok:     true,
+			input:  "CREATE TABLE u (a BOOLEAN, b BIGINT, c REAL, d DOUBLE, e DATE, f TIMESTAMP, g BLOB)",
+			types:  []string{"boolean", "bigint", "real", "double", "date", "timestamp", "blob"},
+			params: [][]string{nil, nil, nil, nil, nil, nil, nil},
+		},
+		{
+			ok:     true,
+			input:  "CREATE TABLE u (name VARCHAR(255), price NUMERIC(10, 2), amount NUMERIC(5), n NUMERIC)",
+			types:  []string{"varchar", "numeric", "numeric", "numeric"},
+			params: [][]string{{"255"}, {"10", "2"}, {"5"}, nil},
+		},
+		// false tests
+		{
+			ok:    false,
+			input: "CREATE TABLE u (id INT(4))",
+		},
+		{
+			ok:    false,
+			input: "CREATE TABLE u (name VARCHAR(1, 2))",
+		},
+		{
+			ok:    false,
+			input: "CREATE TABLE u (name VARCHAR())",
+		},
+		{
+			ok:    false,
+			input: "CREATE TABLE u (name VARCHAR('a'))",
+		},
+		{
+			ok:    false,
+			input: "CREATE TABLE u (name VARCHAR(1.5))",
+		},
+		{
+			ok:    false,
+			input: "CREATE TABLE u (price NUMERIC(10, 1e2))",
+		},
+		{
+			ok:    false,
+			input: "CREATE TABLE u (price NUMERIC(2, 9))",
+		},
+		{
+			ok:    false,
+			input: "CREATE TABLE u (name select)",
+		},
+	}
+
+	for _, test := range tests {
+		ast, err := Parse(test.input)
+		assert.Equal(t, test.ok, err == nil, test.input)
+
+		if !test.ok {
+			continue
+		}
+
+		assert.Len(t, ast.Statements, 1, test.input)
+
+		stmt := ast.Statements[0]
+		assert.Equal(t, CreateTableKind, stmt.Kind, test.input)
+
+		cols := *stmt.CreateTableStatement.Cols
+		assert.Len(t, cols, len(test.types), test.input)
+
+		for i, col := range cols {
+			assert.Equal(t, test.types[i], col.Datatype.Name.Value, test.input)
+
+			var params []string
+			for _, p := range col.Datatype.Params {
+				params = append(params, p.Value)
+			}
+
+			assert.Equal(t, test.params[i], params, test.input)
+		}
+	}
+}
+
+func TestParse_literals(t *testing.T) {
+	tests := []struct {
+		input string
+		kinds []lexer.TokenKind
+	}{
+		{
+			input: "SELECT 1, 'a', true, FALSE, id FROM users;",
+			kinds: []lexer.TokenKind{
+				lexer.NumericKind,
+				lexer.StringKind,
+				lexer.BoolKind,
+				lexer.BoolKind,
+				lexer.IdentifierKind,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		ast, err := Parse(test.input)
+		assert.Nil(t, err, test.input)
+
+		items := ast.Statements[0].SelectStatement.Item
+		assert.Len(t, items, len(test.kinds), test.input)
+
+		for i, item := range items {
+			assert.Equal(t, LiteralKind, item.Kind, test.input)
+			assert.Equal(t, test.kinds[i], item.Literal.Kind, test.input)
+		}
+	}
+}
+
+func TestParse_insert(t *testing.T) {
+	ast, err := Parse("INSERT INTO users VALUES (105, 'a', true);")
+	assert.Nil(t, err)
+	assert.Len(t, ast.Statements, 1)
+
+	stmt := ast.Statements[0]
+	assert.Equal(t, InsertKind, stmt.Kind)
+	assert.Equal(t, "users", stmt.InsertStatement.Table.Value)
+	assert.Len(t, *stmt.InsertStatement.Values, 3)
+}
+
+// stringify renders an expression as an s-expression so tests can assert
+// on its shape
+func stringify(e *Expression) string {
+	switch e.Kind {
+	case LiteralKind:
+		if e.Table != nil {
+			return e.Table.Value + "." + e.Literal.Value
+		}
+
+		return e.Literal.Value
+	case BinaryKind:
+		op := e.Binary.Op.Value
+		if e.Binary.Not {
+			op += " not"
+		}
+
+		s := "(" + op + " " + stringify(&e.Binary.A) + " " + stringify(&e.Binary.B)
+
+		if e.Binary.Escape != nil {
+			s += " " + stringify(e.Binary.Escape)
+		}
+
+		return s + ")"
+	case UnaryKind:
+		return "(" + e.Unary.Op.Value + " " + stringify(&e.Unary.Operand) + ")"
+	case CallKind:
+		var args []string
+		for _, arg := range e.Call.Args {
+			args = append(args, stringify(arg))
+		}
+
+		distinct := ""
+		if e.Call.Distinct {
+			distinct = "distinct "
+		}
+
+		s := e.Call.Name.Value + "(" + distinct + strings.Join(args, ", ") + ")"
+
+		if e.Call.Over != nil {
+			s += " over " + stringifyWindow(e.Call.Over)
+		}
+
+		return s
+	case SubqueryKind:
+		return stringifySelect(e.Subquery)
+	case BetweenKind:
+		op := "between"
+		if e.Between.Not {
+			op += " not"
+		}
+
+		return "(" + op + " " + stringify(&e.Between.Operand) + " " + stringify(&e.Between.Low) + " " + stringify(&e.Between.High) + ")"
+	case ListKind:
+		var items []string
+		for _, item := range e.List {
+			items = append(items, stringify(item))
+		}
+
+		return "[" + strings.Join(items, ", ") + "]"
+	case CaseKind:
+		s := "(case"
+
+		if e.Case.Operand != nil {
+			s += " " + stringify(e.Case.Operand)
+		}
+
+		for _, when := range e.Case.Whens {
+			s += " (when " + stringify(&when.When) + " " + stringify(&when.Then) + ")"
+		}
+
+		if e.Case.Else != nil {
+			s += " (else " + stringify(e.Case.Else) + ")"
+		}
+
+		return s + ")"
+	case CastKind:
+		dt := e.Cast.Type.Name.Value
+
+		if e.Cast.Type.Params != nil {
+			var params []string
+			for _, p := range e.Cast.Type.Params {
+				params = append(params, p.Value)
+			}
+
+			dt += "(" + strings.Join(params, ", ") + ")"
+		}
+
+		return "(" + e.Cast.Op.Value + " " + stringify(&e.Cast.Operand) + " " + dt + ")"
+	}
+
+	return "?"
+}
+
+func stringifyOrderBy(orderBy []*OrderByItem) string {
+	var items []string
+	for _, item := range orderBy {
+		o := stringify(item.Exp)
+		if item.Desc {
+			o += " desc"
+		}
+
+		items = append(items, o)
+	}
+
+	return "order by " + strings.Join(items, ", ")
+}
+
+func stringifyFrameBound(bound *FrameBound) string {
+	switch bound.Kind {
+	case UnboundedPrecedingKind:
+		return "unbounded preceding"
+	case PrecedingKind:
+		return stringify(bound.Offset) + " preceding"
+	case CurrentRowKind:
+		return "current row"
+	case FollowingKind:
+		return stringify(bound.Offset) + " following"
+	case UnboundedFollowingKind:
+		return "unbounded following"
+	}
+
+	return "?"
+}
+
+func stringifyWindow(window *WindowDefinition) string {
+	var clauses []string
+
+	if window.PartitionBy != nil {
+		var exps []string
+		for _, exp := range window.PartitionBy {
+			exps = append(exps, stringify(exp))
+		}
+
+		clauses = append(clauses, "partition by "+strings.Join(exps, ", "))
+	}
+
+	if window.OrderBy != nil {
+		clauses = append(clauses, stringifyOrderBy(window.OrderBy))
+	}
+
+	if window.Frame != nil {
+		frame := window.Frame.Unit.Value + " "
+
+		if window.Frame.End != nil {
+			frame += "between " + stringifyFrameBound(&window.Frame.Start) + " and " + stringifyFrameBound(window.Frame.End)
+		} else {
+			frame += stringifyFrameBound(&window.Frame.Start)
+		}
+
+		clauses = append(clauses, frame)
+	}
+
+	return "(" + strings.Join(clauses, " ") + ")"
+}
+
+func stringifySelect(slct *SelectStatement) string {
+	var items []string
+	for _, item := range slct.Item {
+		items = append(items, stringify(item))
+	}
+
+	s := "("
+
+	if slct.With != nil {
+		s += stringifyWith(slct.With) + " "
+	}
+
+	if slct.SetOperation != nil {
+		op := slct.SetOperation.Op.Value
+		if slct.SetOperation.All {
+			op += " all"
+		}
+
+		s += op + " " + stringifySelect(slct.SetOperation.Left) + " " + stringifySelect(slct.SetOperation.Right)
+	} else {
+		s += "select "
+
+		if slct.DistinctOn != nil {
+			var exps []string
+			for _, exp := range slct.DistinctOn {
+				exps = append(exps, stringify(exp))
+			}
+
+			s += "distinct on (" + strings.Join(exps, ", ") + ") "
+		} else if slct.Distinct {
+			s += "distinct "
+		}
+
+		s += strings.Join(items, ", ")
+	}
+
+	if slct.From != nil {
+		s += " from "
+
+		if slct.From.Kind == SubqueryFromKind {
+			s += stringifySelect(slct.From.Subquery)
+		} else {
+			s += slct.From.Table.Value
+		}
+
+		if slct.From.Alias != nil {
+			s += " " + slct.From.Alias.Value
+		}
+	}
+
+	if slct.Where != nil {
+		s += " where " + stringify(slct.Where)
+	}
+
+	if slct.OrderBy != nil {
+		s += " " + stringifyOrderBy(slct.OrderBy)
+	}
+
+	if slct.Limit != nil {
+		s += " limit " + stringify(slct.Limit)
+	}
+
+	if slct.Offset != nil {
+		s += " offset " + stringify(slct.Offset)
+	}
+
+	return s + ")"
+}
+
+func TestParse_where(t *testing.T) {
+	tests := []struct {
+		ok    bool
+		input string
+		where string
+	}{
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a = 1;",
+			where: "(= a 1)",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a = 1 AND b <> 2 OR c >= 3;",
+			where: "(or (and (= a 1) (<> b 2)) (>= c 3))",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a = 1 AND (b != 2 OR c < 3);",
+			where: "(and (= a 1) (or (!= b 2) (< c 3)))",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE t.a = .5 AND \"T\".date;",
+			where: "(and (= t.a .5) T.date)",
+		},
+		{
+			ok:    false,
+			input: "SELECT a FROM t WHERE t. = 1;",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE NOT a = 1 AND b;",
+			where: "(and (not (= a 1)) b)",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a IS NULL;",
+			where: "(is a null)",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a IS NOT NULL AND b IS TRUE;",
+			where: "(and (is not a null) (is b true))",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a IS DISTINCT FROM b;",
+			where: "(distinct a b)",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a IS NOT DISTINCT FROM NULL OR b;",
+			where: "(or (distinct not a null) b)",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE COALESCE(a, NULL, 1) = NULLIF(b, 0);",
+			where: "(= coalesce(a, null, 1) nullif(b, 0))",
+		},
+		{
+			ok:    true,
+			input: "SELECT a WHERE a <= 1;",
+			where: "(<= a 1)",
+		},
+		// false tests
+		{
+			ok:    false,
+			input: "SELECT a FROM t WHERE a IS 1;",
+		},
+		{
+			ok:    false,
+			input: "SELECT a FROM t WHERE a IS DISTINCT b;",
+		},
+		{
+			ok:    false,
+			input: "SELECT a FROM t WHERE a = ;",
+		},
+		{
+			ok:    false,
+			input: "SELECT a FROM t WHERE (a = 1;",
+		},
+	}
+
+	for _, test := range tests {
+		ast, err := Parse(test.input)
+		assert.Equal(t, test.ok, err == nil, test.input)
+
+		if !test.ok {
+			continue
+		}
+
+		where := ast.Statements[0].SelectStatement.Where
+		assert.NotNil(t, where, test.input)
+		assert.Equal(t, test.where, stringify(where), test.input)
+	}
+}
+
+func TestParse_selectItems(t *testing.T) {
+	ast, err := Parse("SELECT COALESCE(a, b), NULLIF(c, ''), now(), NULL FROM t;")
+	assert.Nil(t, err)
+
+	var items []string
+	for _, item := range ast.Statements[0].SelectStatement.Item {
+		items = append(items, stringify(item))
+	}
+
+	assert.Equal(t, []string{"coalesce(a, b)", "nullif(c, )", "now()", "null"}, items)
+
+	ast, err = Parse("SELECT *, t.*, count(*) FROM t UNION SELECT a, b FROM u;")
+	assert.Nil(t, err)
+
+	items = nil
+	left := ast.Statements[0].SelectStatement.SetOperation.Left
+
+	for _, item := range left.Item {
+		items = append(items, stringify(item))
+		assert.Equal(t, item.Kind == LiteralKind, item.Star())
+	}
+
+	assert.Equal(t, []string{"*", "t.*", "count(*)"}, items)
+
+	for _, input := range []string{"SELECT a FROM t WHERE * = 1;", "SELECT t. FROM t;", "SELECT (*) FROM t;"} {
+		_, err := Parse(input)
+		assert.NotNil(t, err, input)
+	}
+}
+
+func TestParse_alterTable(t *testing.T) {
+	tests := []struct {
+		ok      bool
+		input   string
+		kind    AlterTableActionKind
+		column  string
+		newName string
+	}{
+		{
+			ok:     true,
+			input:  "ALTER TABLE u ADD COLUMN age INT DEFAULT 0;",
+			kind:   AddColumnKind,
+			column: "age",
+		},
+		{
+			ok:     true,
+			input:  "ALTER TABLE u ADD age INT;",
+			kind:   AddColumnKind,
+			column: "age",
+		},
+		{
+			ok:     true,
+			input:  "ALTER TABLE u DROP COLUMN age;",
+			kind:   DropColumnKind,
+			column: "age",
+		},
+		{
+			ok:     true,
+			input:  "ALTER TABLE u DROP age;",
+			kind:   DropColumnKind,
+			column: "age",
+		},
+		{
+			ok:      true,
+			input:   "ALTER TABLE u RENAME COLUMN age TO years;",
+			kind:    RenameColumnKind,
+			column:  "age",
+			newName: "years",
+		},
+		{
+			ok:      true,
+			input:   "ALTER TABLE u RENAME age TO years;",
+			kind:    RenameColumnKind,
+			column:  "age",
+			newName: "years",
+		},
+		{
+			ok:      true,
+			input:   "ALTER TABLE u RENAME TO users;",
+			kind:    RenameTableKind,
+			newName: "users",
+		},
+		// false tests
+		{
+			ok:    false,
+			input: "ALTER TABLE u;",
+		},
+		{
+			ok:    false,
+			input: "ALTER TABLE u ADD COLUMN age;",
+		},
+		{
+			ok:    false,
+			input: "ALTER TABLE u RENAME COLUMN age years;",
+		},
+		{
+			ok:    false,
+			input: "ALTER TABLE u RENAME TO;",
+		},
+	}
+
+	for _, test := range tests {
+		ast, err := Parse(test.input)
+		assert.Equal(t, test.ok, err == nil, test.input)
+
+		if !test.ok {
+			continue
+		}
+
+		stmt := ast.Statements[0]
+		assert.Equal(t, AlterTableKind, stmt.Kind, test.input)
+
+		alter := stmt.AlterTableStatement
+		assert.Equal(t, "u", alter.Name.Value, test.input)
+		assert.Equal(t, test.kind, alter.Kind, test.input)
+		assert.Equal(t, test.newName, alter.NewName.Value, test.input)
+
+		if test.kind == AddColumnKind {
+			assert.Equal(t, test.column, alter.AddColumn.Name.Value, test.input)
+		} else {
+			assert.Equal(t, test.column, alter.Column.Value, test.input)
+		}
+	}
+}
+
+func TestParse_columnDefault(t *testing.T) {
+	ast, err := Parse("CREATE TABLE u (id INT DEFAULT 1, name TEXT, active BOOLEAN DEFAULT NOT false);")
+	assert.Nil(t, err)
+
+	var defaults []string
+	for _, col := range *ast.Statements[0].CreateTableStatement.Cols {
+		if col.Default == nil {
+			defaults = append(defaults, "")
+			continue
+		}
+
+		defaults = append(defaults, stringify(col.Default))
+	}
+
+	assert.Equal(t, []string{"1", "", "(not false)"}, defaults)
+}
+
+func stringifyWith(with *WithClause) string {
+	var ctes []string
+	for _, cte := range with.Ctes {
+		name := cte.Name.Value
+
+		if cte.Columns != nil {
+			var cols []string
+			for _, col := range cte.Columns {
+				cols = append(cols, col.Value)
+			}
+
+			name += "(" + strings.Join(cols, ", ") + ")"
+		}
+
+		ctes = append(ctes, name+" "+stringifySelect(cte.Query))
+	}
+
+	s := "with "
+
+	if with.Recursive {
+		s += "recursive "
+	}
+
+	return s + strings.Join(ctes, ", ")
+}
+
+func TestParse_subqueries(t *testing.T) {
+	tests := []struct {
+		ok    bool
+		input string
+		query string
+	}{
+		{
+			ok:    true,
+			input: "SELECT (SELECT max(b) FROM t2) FROM t;",
+			query: "(select (select max(b) from t2) from t)",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a IN (SELECT b FROM t2);",
+			query: "(select a from t where (in a (select b from t2)))",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a NOT IN (SELECT b FROM t2) AND b;",
+			query: "(select a from t where (and (in not a (select b from t2)) b))",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE EXISTS (SELECT 1 FROM t2 WHERE b = a);",
+			query: "(select a from t where (exists (select 1 from t2 where (= b a))))",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE NOT EXISTS (SELECT 1 FROM t2);",
+			query: "(select a from t where (not (exists (select 1 from t2))))",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a = (SELECT b FROM t2);",
+			query: "(select a from t where (= a (select b from t2)))",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM (SELECT a FROM t) AS d;",
+			query: "(select a from (select a from t) d)",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM (SELECT a FROM (SELECT a FROM t) x) d WHERE a = 1;",
+			query: "(select a from (select a from (select a from t) x) d where (= a 1))",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM users u;",
+			query: "(select a from users u)",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM users AS u WHERE a = 1;",
+			query: "(select a from users u where (= a 1))",
+		},
+		// false tests
+		{
+			ok:    false,
+			input: "SELECT a FROM (SELECT a FROM t);",
+		},
+		{
+			ok:    false,
+			input: "SELECT a FROM users AS;",
+		},
+		{
+			ok:    false,
+			input: "SELECT a FROM t WHERE a IN 1;",
+		},
+		{
+			ok:    false,
+			input: "SELECT a FROM t WHERE a NOT = 1;",
+		},
+		{
+			ok:    false,
+			input: "SELECT a FROM t WHERE EXISTS 1;",
+		},
+		{
+			ok:    false,
+			input: "SELECT (SELECT a FROM t FROM t;",
+		},
+		{
+			ok:    false,
+			input: "SELECT 1 WHERE (SELECT);",
+		},
+		{
+			ok:    false,
+			input: "SELECT;",
+		},
+	}
+
+	for _, test := range tests {
+		ast, err := Parse(test.input)
+		assert.Equal(t, test.ok, err == nil, test.input)
+
+		if !test.ok {
+			continue
+		}
+
+		assert.Equal(t, test.query, stringifySelect(ast.Statements[0].SelectStatement), test.input)
+	}
+}
+
+func TestParse_with(t *testing.T) {
+	tests := []struct {
+		ok    bool
+		input string
+		query string
+	}{
+		{
+			ok:    true,
+			input: "WITH x AS (SELECT a FROM t) SELECT a FROM x;",
+			query: "(with x (select a from t) select a from x)",
+		},
+		{
+			ok:    true,
+			input: "WITH x (b, c) AS (SELECT a, a FROM t), y AS (SELECT b FROM x) SELECT b FROM y;",
+			query: "(with x(b, c) (select a, a from t), y (select b from x) select b from y)",
+		},
+		{
+			ok:    true,
+			input: "WITH RECURSIVE tree (id) AS (SELECT id FROM nodes) SELECT id FROM tree;",
+			query: "(with recursive tree(id) (select id from nodes) select id from tree)",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a IN (WITH x AS (SELECT b FROM t2) SELECT b FROM x);",
+			query: "(select a from t where (in a (with x (select b from t2) select b from x)))",
+		},
+		// false tests
+		{
+			ok:    false,
+			input: "WITH x (SELECT a FROM t) SELECT a FROM x;",
+		},
+		{
+			ok:    false,
+			input: "WITH x AS SELECT a FROM t SELECT a FROM x;",
+		},
+		{
+			ok:    false,
+			input: "WITH x () AS (SELECT a FROM t) SELECT a FROM x;",
+		},
+		{
+			ok:    false,
+			input: "WITH x AS (SELECT a FROM t);",
+		},
+	}
+
+	for _, test := range tests {
+		ast, err := Parse(test.input)
+		assert.Equal(t, test.ok, err == nil, test.input)
+
+		if !test.ok {
+			continue
+		}
+
+		assert.Equal(t, test.query, stringifySelect(ast.Statements[0].SelectStatement), test.input)
+	}
+}
+
+func TestParse_insertWith(t *testing.T) {
+	ast, err := Parse("WITH x AS (SELECT a FROM t) INSERT INTO u VALUES ((SELECT a FROM x));")
+	assert.Nil(t, err)
+
+	insert := ast.Statements[0].InsertStatement
+	assert.Equal(t, "with x (select a from t)", stringifyWith(insert.With))
+	assert.Equal(t, "u", insert.Table.Value)
+}
+
+func TestParse_setOperations(t *testing.T) {
+	tests := []struct {
+		ok    bool
+		input string
+		query string
+	}{
+		{
+			ok:    true,
+			input: "SELECT a FROM t UNION SELECT b FROM u;",
+			query: "(union (select a from t) (select b from u))",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t UNION ALL SELECT b FROM u EXCEPT SELECT c FROM v;",
+			query: "(except (union all (select a from t) (select b from u)) (select c from v))",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t UNION SELECT b FROM u INTERSECT SELECT c FROM v;",
+			query: "(union (select a from t) (intersect (select b from u) (select c from v)))",
+		},
+		{
+			ok:    true,
+			input: "(SELECT a FROM t UNION SELECT b FROM u) INTERSECT DISTINCT SELECT c FROM v;",
+			query: "(intersect (union (select a from t) (select b from u)) (select c from v))",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t UNION SELECT b FROM u ORDER BY a DESC, b LIMIT 10 OFFSET 5;",
+			query: "(union (select a from t) (select b from u) order by a desc, b limit 10 offset 5)",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a = 1 ORDER BY a ASC LIMIT 1;",
+			query: "(select a from t where (= a 1) order by a limit 1)",
+		},
+		{
+			ok:    true,
+			input: "SELECT a ORDER BY a;",
+			query: "(select a order by a)",
+		},
+		{
+			ok:    true,
+			input: "WITH RECURSIVE n (i) AS (SELECT 1 UNION ALL SELECT i FROM n WHERE i < 10) SELECT i FROM n;",
+			query: "(with recursive n(i) (union all (select 1) (select i from n where (< i 10))) select i from n)",
+		},
+		// false tests
+		{
+			ok:    false,
+			input: "SELECT a, b FROM t UNION SELECT c FROM u;",
+		},
+		{
+			ok:    false,
+			input: "SELECT a FROM t UNION;",
+		},
+		{
+			ok:    false,
+			input: "SELECT a FROM t ORDER a;",
+		},
+		{
+			ok:    false,
+			input: "(SELECT a FROM t LIMIT 1) LIMIT 2;",
+		},
+		{
+			ok:    false,
+			input: "SELECT a FROM t LIMIT;",
+		},
+	}
+
+	for _, test := range tests {
+		ast, err := Parse(test.input)
+		assert.Equal(t, test.ok, err == nil, test.input)
+
+		if !test.ok {
+			continue
+		}
+
+		assert.Equal(t, test.query, stringifySelect(ast.Statements[0].SelectStatement), test.input)
+	}
+}
+
+func TestParse_windowFunctions(t *testing.T) {
+	tests := []struct {
+		ok    bool
+		input string
+		item  string
+	}{
+		{
+			ok:    true,
+			input: "SELECT ROW_NUMBER() OVER () FROM t;",
+			item:  "row_number() over ()",
+		},
+		{
+			ok:    true,
+			input: "SELECT rank() OVER (PARTITION BY dept ORDER BY salary DESC) FROM t;",
+			item:  "rank() over (partition by dept order by salary desc)",
+		},
+		{
+			ok:    true,
+			input: "SELECT dense_rank() OVER (PARTITION BY a, b) FROM t;",
+			item:  "dense_rank() over (partition by a, b)",
+		},
+		{
+			ok:    true,
+			input: "SELECT count(*) OVER (PARTITION BY a) FROM t;",
+			item:  "count(*) over (partition by a)",
+		},
+		{
+			ok:    true,
+			input: "SELECT lag(x, 1) OVER (ORDER BY d) FROM t;",
+			item:  "lag(x, 1) over (order by d)",
+		},
+		{
+			ok:    true,
+			input: "SELECT sum(x) OVER (ORDER BY d ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) FROM t;",
+			item:  "sum(x) over (order by d rows between unbounded preceding and current row)",
+		},
+		{
+			ok:    true,
+			input: "SELECT avg(x) OVER (ORDER BY d ROWS BETWEEN 2 PRECEDING AND 2 FOLLOWING) FROM t;",
+			item:  "avg(x) over (order by d rows between 2 preceding and 2 following)",
+		},
+		{
+			ok:    true,
+			input: "SELECT first_value(x) OVER (PARTITION BY a RANGE UNBOUNDED PRECEDING) FROM t;",
+			item:  "first_value(x) over (partition by a range unbounded preceding)",
+		},
+		{
+			ok:    true,
+			input: "SELECT lead(x) OVER (RANGE BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING) FROM t;",
+			item:  "lead(x) over (range between current row and unbounded following)",
+		},
+		// false tests
+		{
+			ok:    false,
+			input: "SELECT rank() OVER FROM t;",
+		},
+		{
+			ok:    false,
+			input: "SELECT rank() OVER (PARTITION dept) FROM t;",
+		},
+		{
+			ok:    false,
+			input: "SELECT sum(x) OVER (ROWS BETWEEN UNBOUNDED FOLLOWING AND CURRENT ROW) FROM t;",
+		},
+		{
+			ok:    false,
+			input: "SELECT sum(x) OVER (ROWS BETWEEN CURRENT ROW AND UNBOUNDED PRECEDING) FROM t;",
+		},
+		{
+			ok:    false,
+			input: "SELECT sum(x) OVER (ROWS 2) FROM t;",
+		},
+		{
+			ok:    false,
+			input: "SELECT sum(x) OVER (ROWS CURRENT) FROM t;",
+		},
+	}
+
+	for _, test := range tests {
+		ast, err := Parse(test.input)
+		assert.Equal(t, test.ok, err == nil, test.input)
+
+		if !test.ok {
+			continue
+		}
+
+		assert.Equal(t, test.item, stringify(ast.Statements[0].SelectStatement.Item[0]), test.input)
+	}
+}
+
+func TestParse_distinct(t *testing.T) {
+	tests := []struct {
+		ok    bool
+		input string
+		query string
+	}{
+		{
+			ok:    true,
+			input: "SELECT DISTINCT a, b FROM t;",
+			query: "(select distinct a, b from t)",
+		},
+		{
+			ok:    true,
+			input: "SELECT ALL a FROM t;",
+			query: "(select a from t)",
+		},
+		{
+			ok:    true,
+			input: "SELECT DISTINCT ON (a, b) a, c FROM t ORDER BY a;",
+			query: "(select distinct on (a, b) a, c from t order by a)",
+		},
+		{
+			ok:    true,
+			input: "SELECT count(DISTINCT a), sum(DISTINCT b), count(ALL c) FROM t;",
+			query: "(select count(distinct a), sum(distinct b), count(c) from t)",
+		},
+		// false tests
+		{
+			ok:    false,
+			input: "SELECT DISTINCT ON a FROM t;",
+		},
+		{
+			ok:    false,
+			input: "SELECT DISTINCT ON () a FROM t;",
+		},
+		{
+			ok:    false,
+			input: "SELECT count(DISTINCT) FROM t;",
+		},
+	}
+
+	for _, test := range tests {
+		ast, err := Parse(test.input)
+		assert.Equal(t, test.ok, err == nil, test.input)
+
+		if !test.ok {
+			continue
+		}
+
+		assert.Equal(t, test.query, stringifySelect(ast.Statements[0].SelectStatement), test.input)
+	}
+}
+
+func TestParse_scalarFunctions(t *testing.T) {
+	tests := []struct {
+		ok    bool
+		input string
+		item  string
+	}{
+		{
+			ok:    true,
+			input: "SELECT lower(a) || upper(b) || 'c' FROM t;",
+			item:  "(|| (|| lower(a) upper(b)) c)",
+		},
+		{
+			ok:    true,
+			input: "SELECT a || b = c FROM t;",
+			item:  "(= (|| a b) c)",
+		},
+		{
+			ok:    true,
+			input: "SELECT length(trim(substr(a, 1, 3))) FROM t;",
+			item:  "length(trim(substr(a, 1, 3)))",
+		},
+		{
+			ok:    true,
+			input: "SELECT replace(a, 'x', 'y') FROM t;",
+			item:  "replace(a, x, y)",
+		},
+		{
+			ok:    true,
+			input: "SELECT mod(abs(round(a, 2)), floor(b)) FROM t;",
+			item:  "mod(abs(round(a, 2)), floor(b))",
+		},
+		{
+			ok:    true,
+			input: "SELECT date_trunc('day', now()) FROM t;",
+			item:  "date_trunc(day, now())",
+		},
+		{
+			ok:    true,
+			input: "SELECT EXTRACT(year FROM date_trunc('month', born)) FROM t;",
+			item:  "extract(year, date_trunc(month, born))",
+		},
+		// false tests
+		{
+			ok:    false,
+			input: "SELECT EXTRACT(year, born) FROM t;",
+		},
+		{
+			ok:    false,
+			input: "SELECT EXTRACT('year' FROM born) FROM t;",
+		},
+		{
+			ok:    false,
+			input: "SELECT EXTRACT year FROM born FROM t;",
+		},
+		{
+			ok:    false,
+			input: "SELECT a || FROM t;",
+		},
+	}
+
+	for _, test := range tests {
+		ast, err := Parse(test.input)
+		assert.Equal(t, test.ok, err == nil, test.input)
+
+		if !test.ok {
+			continue
+		}
+
+		assert.Equal(t, test.item, stringify(ast.Statements[0].SelectStatement.Item[0]), test.input)
+	}
+}
+
+func TestParse_caseAndCast(t *testing.T) {
+	tests := []struct {
+		ok    bool
+		input string
+		item  string
+	}{
+		{
+			ok:    true,
+			input: "SELECT CASE WHEN a > 1 THEN 'big' WHEN a IS NULL THEN 'none' ELSE 'small' END FROM t;",
+			item:  "(case (when (> a 1) big) (when (is a null) none) (else small))",
+		},
+		{
+			ok:    true,
+			input: "SELECT CASE a WHEN 1 THEN 'one' WHEN 2 THEN 'two' END FROM t;",
+			item:  "(case a (when 1 one) (when 2 two))",
+		},
+		{
+			ok:    true,
+			input: "SELECT CASE WHEN CASE WHEN a THEN b END THEN 1 END = 1 FROM t;",
+			item:  "(= (case (when (case (when a b)) 1)) 1)",
+		},
+		{
+			ok:    true,
+			input: "SELECT CAST(a AS INT) FROM t;",
+			item:  "(cast a int)",
+		},
+		{
+			ok:    true,
+			input: "SELECT CAST(a || b AS VARCHAR(10)) FROM t;",
+			item:  "(cast (|| a b) varchar(10))",
+		},
+		{
+			ok:    true,
+			input: "SELECT a::NUMERIC(10, 2) FROM t;",
+			item:  "(:: a numeric(10, 2))",
+		},
+		{
+			ok:    true,
+			input: "SELECT a || b::text FROM t;",
+			item:  "(|| a (:: b text))",
+		},
+		{
+			ok:    true,
+			input: "SELECT '1'::int::bigint = 1 FROM t;",
+			item:  "(= (:: (:: 1 int) bigint) 1)",
+		},
+		// false tests
+		{
+			ok:    false,
+			input: "SELECT CASE END FROM t;",
+		},
+		{
+			ok:    false,
+			input: "SELECT CASE WHEN a THEN b FROM t;",
+		},
+		{
+			ok:    false,
+			input: "SELECT CASE WHEN a b END FROM t;",
+		},
+		{
+			ok:    false,
+			input: "SELECT CAST(a INT) FROM t;",
+		},
+		{
+			ok:    false,
+			input: "SELECT CAST(a AS b) FROM t;",
+		},
+		{
+			ok:    false,
+			input: "SELECT a:: FROM t;",
+		},
+	}
+
+	for _, test := range tests {
+		ast, err := Parse(test.input)
+		assert.Equal(t, test.ok, err == nil, test.input)
+
+		if !test.ok {
+			continue
+		}
+
+		assert.Equal(t, test.item, stringify(ast.Statements[0].SelectStatement.Item[0]), test.input)
+	}
+}
+
+func TestParse_predicates(t *testing.T) {
+	tests := []struct {
+		ok    bool
+		input string
+		where string
+	}{
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a LIKE 'ab%';",
+			where: "(like a ab%)",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a NOT ILIKE '_b' || c AND d;",
+			where: "(and (ilike not a (|| _b c)) d)",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a LIKE 'a!%%' ESCAPE '!' OR b;",
+			where: "(or (like a a!%% !) b)",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a BETWEEN 1 AND 10;",
+			where: "(between a 1 10)",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a NOT BETWEEN b AND c || d AND e;",
+			where: "(and (between not a b (|| c d)) e)",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a IN (1, 2, 3);",
+			where: "(in a [1, 2, 3])",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a NOT IN ('x') AND b IN (SELECT b FROM u);",
+			where: "(and (in not a [x]) (in b (select b from u)))",
+		},
+		{
+			ok:    true,
+			input: "SELECT a FROM t WHERE a BETWEEN 1 AND 2 = b;",
+			where: "(= (between a 1 2) b)",
+		},
+		// false tests
+		{
+			ok:    false,
+			input: "SELECT a FROM t WHERE a IN ();",
+		},
+		{
+			ok:    false,
+			input: "SELECT a FROM t WHERE a IN (1, 2;",
+		},
+		{
+			ok:    false,
+			input: "SELECT a FROM t WHERE a BETWEEN 1;",
+		},
+		{
+			ok:    false,
+			input: "SELECT a FROM t WHERE a LIKE;",
+		},
+		{
+			ok:    false,
+			input: "SELECT a FROM t WHERE a LIKE 'x' ESCAPE;",
+		},
+	}
+
+	for _, test := range tests {
+		ast, err := Parse(test.input)
+		assert.Equal(t, test.ok, err == nil, test.input)
+
+		if !test.ok {
+			continue
+		}
+
+		assert.Equal(t, test.where, stringify(ast.Statements[0].SelectStatement.Where), test.input)
+	}
+}
+
+func TestParse_schemas(t *testing.T) {
+	ast, err := Parse(`CREATE SCHEMA audit; DROP SCHEMA audit; ATTACH DATABASE 'archive.db' AS archive; ATTACH 'b.db' AS b;
+		CREATE TABLE audit.log (at TIMESTAMP); ALTER TABLE audit.log RENAME TO entries; INSERT INTO archive.users VALUES (1);
+		SELECT a FROM archive.users AS u WHERE u.a = 1; SELECT a FROM users;`)
+	assert.Nil(t, err)
+
+	var kinds []AstKind
+
+	for _, stmt := range ast.Statements {
+		kinds = append(kinds, stmt.Kind)
+	}
+
+	assert.Equal(t, []AstKind{CreateSchemaKind, DropSchemaKind, AttachKind, AttachKind, CreateTableKind, AlterTableKind, InsertKind, SelectKind, SelectKind}, kinds)
+
+	assert.Equal(t, "audit", ast.Statements[0].CreateSchemaStatement.Name.Value)
+	assert.Equal(t, "audit", ast.Statements[1].DropSchemaStatement.Name.Value)
+	assert.Equal(t, "archive.db", ast.Statements[2].AttachStatement.File.Value)
+	assert.Equal(t, "archive", ast.Statements[2].AttachStatement.Name.Value)
+	assert.Equal(t, "b", ast.Statements[3].AttachStatement.Name.Value)
+	assert.Equal(t, "audit", ast.Statements[4].CreateTableStatement.Schema.Value)
+	assert.Equal(t, "log", ast.Statements[4].CreateTableStatement.Name.Value)
+	assert.Equal(t, "audit", ast.Statements[5].AlterTableStatement.Schema.Value)
+	assert.Equal(t, "archive", ast.Statements[6].InsertStatement.Schema.Value)
+	assert.Equal(t, "users", ast.Statements[6].InsertStatement.Table.Value)
+	assert.Equal(t, "archive", ast.Statements[7].SelectStatement.From.Schema.Value)
+	assert.Equal(t, "users", ast.Statements[7].SelectStatement.From.Table.Value)
+	assert.Nil(t, ast.Statements[8].SelectStatement.From.Schema)
+
+	for _, input := range []string{"DROP TABLE t;", "ATTACH archive AS a;", "ATTACH 'a.db';", "SELECT a FROM s.;", "CREATE SCHEMA;"} {
+		_, err := Parse(input)
+		assert.NotNil(t, err, input)
+	}
+}
+
+func TestParse_views(t *testing.T) {
+	ast, err := Parse(`CREATE VIEW active AS SELECT id FROM users WHERE active;
+		CREATE MATERIALIZED VIEW audit.totals (id, total) AS WITH o AS (SELECT id FROM orders) SELECT id, sum(total) FROM o;
+		REFRESH MATERIALIZED VIEW audit.totals; DROP VIEW active; DROP MATERIALIZED VIEW audit.totals;`)
+	assert.Nil(t, err)
+
+	var kinds []AstKind
+
+	for _, stmt := range ast.Statements {
+		kinds = append(kinds, stmt.Kind)
+	}
+
+	assert.Equal(t, []AstKind{CreateViewKind, CreateViewKind, RefreshViewKind, DropViewKind, DropViewKind}, kinds)
+
+	view := ast.Statements[0].CreateViewStatement
+	assert.Equal(t, "active", view.Name.Value)
+	assert.False(t, view.Materialized)
+	assert.Equal(t, "active", stringify(view.Query.Where))
+
+	view = ast.Statements[1].CreateViewStatement
+	assert.Equal(t, "audit", view.Schema.Value)
+	assert.True(t, view.Materialized)
+	assert.Len(t, view.Columns, 2)
+	assert.Equal(t, "o", view.Query.With.Ctes[0].Name.Value)
+
+	assert.Equal(t, "totals", ast.Statements[2].RefreshViewStatement.Name.Value)
+	assert.False(t, ast.Statements[3].DropViewStatement.Materialized)
+	assert.True(t, ast.Statements[4].DropViewStatement.Materialized)
+
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{"CREATE VIEW v SELECT a FROM t;", "[0,14]: Expected AS, got: select"},
+		{"CREATE MATERIALIZED TABLE t (a INT);", "[0,20]: Expected VIEW, got: table"},
+		{"REFRESH VIEW v;", "[0,8]: Expected MATERIALIZED VIEW, got: view"},
+		{"DROP TABLE t;", "[0,5]: Expected SCHEMA or VIEW, got: table"},
+		{"CREATE VIEW v AS INSERT INTO t VALUES (1);", "[0,17]: Expected query, got: insert"},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.input)
+		assert.Equal(t, test.err, err.Error(), test.input)
+	}
+}
+
+func TestParse_triggers(t *testing.T) {
+	ast, err := Parse(`CREATE TRIGGER log AFTER INSERT ON users FOR EACH ROW WHEN NEW.active BEGIN
+			INSERT INTO audit.log VALUES (NEW.id, CASE WHEN NEW.name IS NULL THEN 'x' ELSE NEW.name END);
+			SELECT id FROM users WHERE id = NEW.id;
+		END;
+		CREATE TRIGGER keep BEFORE DELETE ON audit.log FOR EACH ROW BEGIN SELECT OLD.at; END;`)
+	assert.Nil(t, err)
+
+	assert.Len(t, ast.Statements, 2)
+
+	trigger := ast.Statements[0].CreateTriggerStatement
+	assert.Equal(t, CreateTriggerKind, ast.Statements[0].Kind)
+	assert.Equal(t, "log", trigger.Name.Value)
+	assert.Equal(t, "after", trigger.Timing.Value)
+	assert.Equal(t, "insert", trigger.Event.Value)
+	assert.Nil(t, trigger.Schema)
+	assert.Equal(t, "users", trigger.Table.Value)
+	assert.Equal(t, "new.active", stringify(trigger.When))
+	assert.Len(t, trigger.Body, 2)
+	assert.Equal(t, InsertKind, trigger.Body[0].Kind)
+	assert.Equal(t, "(= id new.id)", stringify(trigger.Body[1].SelectStatement.Where))
+
+	trigger = ast.Statements[1].CreateTriggerStatement
+	assert.Equal(t, "before", trigger.Timing.Value)
+	assert.Equal(t, "delete", trigger.Event.Value)
+	assert.Equal(t, "audit", trigger.Schema.Value)
+	assert.Nil(t, trigger.When)
+
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{"CREATE TRIGGER t ON users FOR EACH ROW BEGIN SELECT 1; END;", "[0,17]: Expected BEFORE or AFTER, got: on"},
+		{"CREATE TRIGGER t AFTER SELECT ON users FOR EACH ROW BEGIN SELECT 1; END;", "[0,23]: Expected INSERT, UPDATE or DELETE, got: select"},
+		{"CREATE TRIGGER t AFTER UPDATE ON users BEGIN SELECT 1; END;", "[0,39]: Expected FOR EACH ROW, got: begin"},
+		{"CREATE TRIGGER t AFTER UPDATE ON users FOR EACH ROW BEGIN END;", "[0,58]: Expected INSERT or SELECT in trigger body, got: end"},
+		{"CREATE TRIGGER t AFTER UPDATE ON users FOR EACH ROW BEGIN SELECT 1 END;", "[0,67]: Expected comma, got: end"},
+		{"CREATE INDEX i ON t (a);", "[0,7]: Expected TABLE, SCHEMA, VIEW or TRIGGER, got: index"},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.input)
+		assert.Equal(t, test.err, err.Error(), test.input)
+	}
+
+	// Recovery skips the whole body, not just its first statement
+	ast, diagnostics := ParseWithDiagnostics("CREATE TRIGGER t AFTER INSERT ON u FOR EACH ROW BEGIN SELECT FROM; SELECT 1; END; SELECT a FROM u;")
+	assert.Len(t, diagnostics, 1)
+	assert.Len(t, ast.Statements, 1)
+	assert.Equal(t, SelectKind, ast.Statements[0].Kind)
+
+	// Only a trigger's BEGIN opens a body, so a stray BEGIN is skipped on
+	// its own
+	ast, diagnostics = ParseWithDiagnostics("SELECT a FROM t WHERE x; BEGIN; INSERT INTO t VALUES (1); BEGIN TRANSACTION; SELECT a FROM t; COMMIT;")
+	assert.Len(t, diagnostics, 3)
+	assert.Len(t, ast.Statements, 3)
+
+	reader := NewStatementReader(strings.NewReader("BEGIN; INSERT INTO t VALUES (1); INSERT INTO t VALUES (2); COMMIT;"))
+	var kinds []AstKind
+
+	for {
+		statement, err := reader.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err == nil {
+			kinds = append(kinds, statement.Kind)
+		}
+	}
+
+	assert.Equal(t, []AstKind{InsertKind, InsertKind}, kinds)
+}
+
+func TestStatementReader(t *testing.T) {
+	input := `CREATE TABLE users (id INT, name TEXT);
+		-- seed data
+		INSERT INTO users VALUES (1, 'a;b');;
+		SELECT id, name FROM users WHERE id IN (SELECT id FROM users) ORDER BY id;
+		CREATE TRIGGER copy AFTER INSERT ON users FOR EACH ROW BEGIN INSERT INTO users VALUES (NEW.id, 'c;d'); SELECT 1; END;
+		SELECT 1;
+		SELECT count(*), now() ORDER BY 1;
+		SELECT name FROM users;
+		SELECT 'no semicolon'`
+
+	expected, err := Parse(input)
+	assert.Nil(t, err)
+
+	reader := NewStatementReader(iotest.OneByteReader(strings.NewReader(input)))
+
+	var statements []*Statement
+
+	for {
+		statement, err := reader.Next()
+
+		if err == io.EOF {
+			break
+		}
+
+		assert.Nil(t, err)
+
+		if err != nil {
+			return
+		}
+
+		statements = append(statements, statement)
+	}
+
+	assert.Equal(t, expected.Statements, statements)
+
+	for _, input := range []string{"SELECT 1 SELECT 2;", "SELECT FROM;", "SELECT 'a", "CREATE TRIGGER t AFTER INSERT ON u FOR EACH ROW BEGIN SELECT 1;"} {
+		_, err := NewStatementReader(strings.NewReader(input)).Next()
+		assert.NotNil(t, err, input)
+	}
+}
+
+func TestParse_keywordsAsIdentifiers(t *testing.T) {
+	for _, kw := range lexer.Keywords() {
+		input := "CREATE TABLE t (" + string(kw) + " INT);"
+		ast, err := Parse(input)
+		assert.Equal(t, !kw.Reserved(), err == nil, input)
+
+		if kw.Reserved() {
+			continue
+		}
+
+		col := (*ast.Statements[0].CreateTableStatement.Cols)[0]
+		assert.Equal(t, lexer.Token{
+			Value: string(kw),
+			Kind:  lexer.IdentifierKind,
+			Loc:   lexer.Location{Col: 16},
+			Span:  lexer.Span{Start: 16, End: 16 + uint(len(kw))},
+			Raw:   string(kw),
+		}, col.Name, input)
+
+		input = "SELECT " + string(kw) + " FROM t AS " + string(kw) + " WHERE " + string(kw) + " = 1;"
+		ast, err = Parse(input)
+		assert.Nil(t, err, input)
+
+		if err != nil {
+			continue
+		}
+
+		query := ast.Statements[0].SelectStatement
+		assert.Equal(t, string(kw), stringify(query.Item[0]), input)
+		assert.Equal(t, string(kw), query.From.Alias.Value, input)
+		assert.Equal(t, "(= "+string(kw)+" 1)", stringify(query.Where), input)
+	}
+}
+
+func TestParse_quotedIdentifiers(t *testing.T) {
+	ast, err := Parse(`SELECT "MyCol", 'MyCol' FROM "MyTable" AS "select";`)
+	assert.Nil(t, err)
+
+	query := ast.Statements[0].SelectStatement
+	assert.Equal(t, lexer.QuotedIdentifierKind, query.Item[0].Literal.Kind)
+	assert.Equal(t, "MyCol", query.Item[0].Literal.Value)
+	assert.Equal(t, lexer.StringKind, query.Item[1].Literal.Kind)
+	assert.Equal(t, "MyTable", query.From.Table.Value)
+	assert.Equal(t, `"MyTable"`, query.From.Table.Raw)
+	assert.Equal(t, "select", query.From.Alias.Value)
+}
+
+func TestParseWithDiagnostics(t *testing.T) {
+	input := `SELECT a FORM t;
+SELECT b FROM t;
+SELEC c FROM t;;
+SELECT row_number() OVER (PARTITON BY a) FROM t;
+INSERT INTO t VALUES (1, );
+SELECT a FROM t WHERE`
+
+	ast, diagnostics := ParseWithDiagnostics(input)
+
+	assert.Len(t, ast.Statements, 1)
+	assert.Equal(t, "b", stringify(ast.Statements[0].SelectStatement.Item[0]))
+
+	assert.Equal(t, []Diagnostic{
+		{
+			Severity:   ErrorSeverity,
+			Start:      lexer.Location{Line: 0, Col: 9},
+			End:        lexer.Location{Line: 0, Col: 13},
+			Message:    "Expected comma",
+			Got:        "form",
+			Suggestion: lexer.FromKeyword,
+		},
+		{
+			Severity:   ErrorSeverity,
+			Start:      lexer.Location{Line: 2, Col: 0},
+			End:        lexer.Location{Line: 2, Col: 5},
+			Message:    "Expected statement",
+			Got:        "selec",
+			Suggestion: lexer.SelectKeyword,
+		},
+		{
+			Severity: WarningSeverity,
+			Start:    lexer.Location{Line: 2, Col: 15},
+			End:      lexer.Location{Line: 2, Col: 16},
+			Message:  "Empty statement",
+		},
+		{
+			Severity:   ErrorSeverity,
+			Start:      lexer.Location{Line: 3, Col: 26},
+			End:        lexer.Location{Line: 3, Col: 34},
+			Message:    "Expected right paren",
+			Got:        "partiton",
+			Suggestion: lexer.PartitionKeyword,
+		},
+		{
+			Severity: ErrorSeverity,
+			Start:    lexer.Location{Line: 4, Col: 25},
+			End:      lexer.Location{Line: 4, Col: 26},
+			Message:  "Expected expression",
+			Got:      ")",
+		},
+		{
+			Severity: ErrorSeverity,
+			Start:    lexer.Location{Line: 5, Col: 21},
+			End:      lexer.Location{Line: 5, Col: 21},
+			Message:  "Expected WHERE conditionals",
+			Got:      "end of input",
+		},
+	}, diagnostics)
+
+	_, err := Parse(input)
+	assert.Equal(t, "[0,9]: Expected comma, got: form, did you mean FROM?", err.Error())
+
+	_, diagnostics = ParseWithDiagnostics("SELECT 'a\nb' FROM t; SELECT #")
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, lexer.Location{Line: 1, Col: 18}, diagnostics[0].Start)
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		distance int
+	}{
+		{"from", "from", 0},
+		{"form", "from", 1},
+		{"selec", "select", 1},
+		{"slect", "select", 1},
+		{"wehre", "where", 1},
+		{"", "abc", 3},
+		{"größe", "grösse", 2},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.distance, editDistance(test.a, test.b), test.a+" "+test.b)
+	}
+
+	assert.Equal(t, lexer.Keyword(""), suggestKeyword("users"))
+}
+
+func TestInspect(t *testing.T) {
+	ast, err := Parse("WITH c AS (SELECT a FROM t) SELECT a || b, f(b) FROM (SELECT b FROM c) AS s WHERE a IN (1, 2) ORDER BY a;")
+	assert.Nil(t, err)
+
+	var names []string
+	var nodes int
+
+	Inspect(ast, func(node Node) bool {
+		nodes++
+
+		if e, ok := node.(*Expression); ok && e.Kind == LiteralKind && e.Literal.Kind == lexer.IdentifierKind {
+			names = append(names, e.Literal.Value)
+		}
+
+		return true
+	})
+
+	assert.Equal(t, []string{"a", "a", "b", "b", "b", "a", "a"}, names)
+	assert.Equal(t, 24, nodes)
+
+	nodes = 0
+	Inspect(ast, func(node Node) bool {
+		nodes++
+		_, ok := node.(*Expression)
+
+		return !ok
+	})
+
+	assert.Equal(t, 17, nodes)
+}
+
+func TestRewrite(t *testing.T) {
+	ast, err := Parse("SELECT a, b FROM t WHERE a = 1 AND (SELECT a FROM u) > coalesce(a, b);")
+	assert.Nil(t, err)
+
+	Rewrite(ast, func(e *Expression) *Expression {
+		if e.Kind == LiteralKind && e.Literal.Value == "a" {
+			renamed := *e
+			renamed.Literal.Value = "x"
+
+			return &renamed
+		}
+
+		if e.Kind == BinaryKind && e.Binary.Op.Value == "=" {
+			return &e.Binary.A
+		}
+
+		return e
+	})
+
+	query := ast.Statements[0].SelectStatement
+	assert.Equal(t, "x", stringify(query.Item[0]))
+	assert.Equal(t, "b", stringify(query.Item[1]))
+	assert.Equal(t, "(and x (> (select x from u) coalesce(x, b)))", stringify(query.Where))
+}
+
+func TestAst_json(t *testing.T) {
+	inputs := []string{
+		"CREATE TABLE t (id INT, name VARCHAR(20) DEFAULT 'n');",
+		"WITH c AS (SELECT a FROM t) INSERT INTO u VALUES (1, E'x', NULL);",
+		"SELECT DISTINCT a, CASE WHEN a THEN 1 END, CAST(b AS TEXT), c NOT BETWEEN 1 AND 2 FROM (SELECT a FROM t) AS s WHERE NOT a IN (1, 2) ORDER BY a DESC LIMIT 1;",
+		"SELECT sum(a) OVER (PARTITION BY b ORDER BY c ROWS BETWEEN 1 PRECEDING AND UNBOUNDED FOLLOWING) FROM t UNION SELECT a FROM u;",
+		"ALTER TABLE t ADD COLUMN c INT; ALTER TABLE t RENAME TO u;",
+	}
+
+	for _, input := range inputs {
+		expected, err := Parse(input)
+		assert.Nil(t, err, input)
+
+		out, err := json.Marshal(expected)
+		assert.Nil(t, err, input)
+
+		var actual Ast
+		assert.Nil(t, json.Unmarshal(out, &actual), input)
+		assert.Equal(t, expected, &actual, input)
+	}
+
+	ast, err := Parse("SELECT a = 1 FROM t;")
+	assert.Nil(t, err)
+
+	out, err := json.Marshal(ast.Statements[0].SelectStatement.Item[0])
+	assert.Nil(t, err)
+	assert.Contains(t, string(out), `"Kind":"binary"`)
+	assert.Contains(t, string(out), `"Kind":"numeric"`)
+
+	var kind ExpressionKind
+	assert.NotNil(t, json.Unmarshal([]byte(`"nope"`), &kind))
+}
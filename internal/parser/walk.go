@@ -0,0 +1,261 @@
+package parser
+
+// Node is any AST node Walk visits: the Ast, statements, expressions and
+// the clauses that hold expressions
+type Node interface {
+	node()
+}
+
+func (*Ast) node()                    {}
+func (*Statement) node()              {}
+func (*SelectStatement) node()        {}
+func (*InsertStatement) node()        {}
+func (*CreateTableStatement) node()   {}
+func (*AlterTableStatement) node()    {}
+func (*CreateSchemaStatement) node()  {}
+func (*DropSchemaStatement) node()    {}
+func (*AttachStatement) node()        {}
+func (*CreateViewStatement) node()    {}
+func (*RefreshViewStatement) node()   {}
+func (*DropViewStatement) node()      {}
+func (*CreateTriggerStatement) node() {}
+func (*WithClause) node()             {}
+func (*CommonTableExpression) node()  {}
+func (*FromItem) node()               {}
+func (*ColumnDefinition) node()       {}
+func (*OrderByItem) node()            {}
+func (*WindowDefinition) node()       {}
+func (*Expression) node()             {}
+
+// Visitor's Visit is called for each node Walk reaches. It returns the
+// visitor to walk the node's children with, or nil to skip them
+type Visitor interface {
+	Visit(node Node) Visitor
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+
+	return nil
+}
+
+// Inspect walks the tree depth first, calling f for every node until it
+// returns false
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// Walk visits node and then, depth first in source order, its children
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	walkExpressions := func(exps []*Expression) {
+		for _, e := range exps {
+			Walk(v, e)
+		}
+	}
+
+	walkOrderBy := func(items []*OrderByItem) {
+		for _, item := range items {
+			Walk(v, item)
+		}
+	}
+
+	switch n := node.(type) {
+	case *Ast:
+		for _, stmt := range n.Statements {
+			Walk(v, stmt)
+		}
+	case *Statement:
+		switch n.Kind {
+		case SelectKind:
+			Walk(v, n.SelectStatement)
+		case InsertKind:
+			Walk(v, n.InsertStatement)
+		case CreateTableKind:
+			Walk(v, n.CreateTableStatement)
+		case AlterTableKind:
+			Walk(v, n.AlterTableStatement)
+		case CreateSchemaKind:
+			Walk(v, n.CreateSchemaStatement)
+		case DropSchemaKind:
+			Walk(v, n.DropSchemaStatement)
+		case AttachKind:
+			Walk(v, n.AttachStatement)
+		case CreateViewKind:
+			Walk(v, n.CreateViewStatement)
+		case RefreshViewKind:
+			Walk(v, n.RefreshViewStatement)
+		case DropViewKind:
+			Walk(v, n.DropViewStatement)
+		case CreateTriggerKind:
+			Walk(v, n.CreateTriggerStatement)
+		}
+	case *SelectStatement:
+		if n.With != nil {
+			Walk(v, n.With)
+		}
+
+		if n.SetOperation != nil {
+			Walk(v, n.SetOperation.Left)
+			Walk(v, n.SetOperation.Right)
+		}
+
+		walkExpressions(n.DistinctOn)
+		walkExpressions(n.Item)
+
+		if n.From != nil {
+			Walk(v, n.From)
+		}
+
+		if n.Where != nil {
+			Walk(v, n.Where)
+		}
+
+		walkOrderBy(n.OrderBy)
+
+		if n.Limit != nil {
+			Walk(v, n.Limit)
+		}
+
+		if n.Offset != nil {
+			Walk(v, n.Offset)
+		}
+	case *InsertStatement:
+		if n.With != nil {
+			Walk(v, n.With)
+		}
+
+		if n.Values != nil {
+			walkExpressions(*n.Values)
+		}
+	case *CreateTableStatement:
+		if n.Cols != nil {
+			for _, col := range *n.Cols {
+				Walk(v, col)
+			}
+		}
+	case *AlterTableStatement:
+		if n.AddColumn != nil {
+			Walk(v, n.AddColumn)
+		}
+	case *CreateViewStatement:
+		Walk(v, n.Query)
+	case *CreateTriggerStatement:
+		if n.When != nil {
+			Walk(v, n.When)
+		}
+
+		for _, stmt := range n.Body {
+			Walk(v, stmt)
+		}
+	case *WithClause:
+		for _, cte := range n.Ctes {
+			Walk(v, cte)
+		}
+	case *CommonTableExpression:
+		Walk(v, n.Query)
+	case *FromItem:
+		if n.Subquery != nil {
+			Walk(v, n.Subquery)
+		}
+	case *ColumnDefinition:
+		if n.Default != nil {
+			Walk(v, n.Default)
+		}
+	case *OrderByItem:
+		Walk(v, n.Exp)
+	case *WindowDefinition:
+		walkExpressions(n.PartitionBy)
+		walkOrderBy(n.OrderBy)
+
+		if n.Frame != nil {
+			for _, bound := range []*FrameBound{&n.Frame.Start, n.Frame.End} {
+				if bound != nil && bound.Offset != nil {
+					Walk(v, bound.Offset)
+				}
+			}
+		}
+	case *Expression:
+		walkExpression(v, n)
+	}
+}
+
+func walkExpression(v Visitor, e *Expression) {
+	switch e.Kind {
+	case BinaryKind:
+		Walk(v, &e.Binary.A)
+		Walk(v, &e.Binary.B)
+
+		if e.Binary.Escape != nil {
+			Walk(v, e.Binary.Escape)
+		}
+	case UnaryKind:
+		Walk(v, &e.Unary.Operand)
+	case CallKind:
+		for _, arg := range e.Call.Args {
+			Walk(v, arg)
+		}
+
+		if e.Call.Over != nil {
+			Walk(v, e.Call.Over)
+		}
+	case SubqueryKind:
+		Walk(v, e.Subquery)
+	case CaseKind:
+		if e.Case.Operand != nil {
+			Walk(v, e.Case.Operand)
+		}
+
+		for _, w := range e.Case.Whens {
+			Walk(v, &w.When)
+			Walk(v, &w.Then)
+		}
+
+		if e.Case.Else != nil {
+			Walk(v, e.Case.Else)
+		}
+	case CastKind:
+		Walk(v, &e.Cast.Operand)
+	case BetweenKind:
+		Walk(v, &e.Between.Operand)
+		Walk(v, &e.Between.Low)
+		Walk(v, &e.Between.High)
+	case ListKind:
+		for _, item := range e.List {
+			Walk(v, item)
+		}
+	}
+}
+
+// Rewrite replaces every expression under node, children first, with what
+// f returns for it. Other nodes are pointers into the tree and can be
+// changed in place while walking it
+func Rewrite(node Node, f func(*Expression) *Expression) {
+	Walk(rewriter(f), node)
+}
+
+type rewriter func(*Expression) *Expression
+
+func (f rewriter) Visit(node Node) Visitor {
+	e, ok := node.(*Expression)
+
+	if !ok {
+		return f
+	}
+
+	walkExpression(f, e)
+
+	if r := f(e); r != nil && r != e {
+		*e = *r
+	}
+
+	// The children have been rewritten already
+	return nil
+}